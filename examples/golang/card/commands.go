@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"card/pkg/audit"
+	"card/pkg/card"
+	"card/pkg/policy"
+	"card/pkg/service"
+	"card/pkg/utils"
+)
+
+// auditKeyEnv names the environment variable holding the audit fingerprint key.
+const auditKeyEnv = "CARD_AUDIT_KEY"
+
+func runCommand(name string, args []string) error {
+	switch name {
+	case "validate":
+		return runValidate(args)
+	case "serve":
+		return runServe(args)
+	case "lint-schemes":
+		return runLintSchemes(args)
+	case "verify-audit":
+		return runVerifyAudit(args)
+	default:
+		return fmt.Errorf("unknown command %q, expected one of: validate, serve, lint-schemes, verify-audit", name)
+	}
+}
+
+// runValidate validates card numbers, e.g. card validate -policies policies.json -policy shop -bins bins.json 4012888888881881
+// or card validate -explain 4012888888881881
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	policiesPath := fs.String("policies", "", "policy config file")
+	policyName := fs.String("policy", "", "name of the policy to apply")
+	binsPath := fs.String("bins", "", "BIN data file, needed by country and funding rules")
+	explain := fs.Bool("explain", false, "trace how the scheme was determined, digits after the IIN are masked")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("validate: no card number given")
+	}
+
+	p, err := loadPolicy(*policiesPath, *policyName)
+	if err != nil {
+		return err
+	}
+	opts, err := loadBINs(*binsPath)
+	if err != nil {
+		return err
+	}
+	validator := card.NewValidator(opts...)
+
+	for _, number := range fs.Args() {
+		if *explain {
+			explanation, err := utils.Explain(number)
+			if err != nil {
+				return err
+			}
+			fmt.Print(explanation)
+		}
+
+		creditCard, err := validator.NewCreditCard(number)
+		if err != nil {
+			return err
+		}
+		if !*explain {
+			// The explanation already showed the masked number, do not print it in clear.
+			fmt.Println(creditCard)
+		}
+
+		if p != nil {
+			info, err := validator.BINInfo(creditCard)
+			if err != nil {
+				return err
+			}
+			decision := p.Evaluate(creditCard, info)
+			result := map[bool]string{true: "accepted", false: "declined"}[decision.Accepted]
+			fmt.Printf("Policy '%s': %s by rule '%s'\n", p.Name, result, decision.Rule)
+		}
+	}
+	return nil
+}
+
+// runServe starts the HTTP validation service, e.g. card serve -addr :8080 -policies policies.json -bins bins.json -audit-log audit.jsonl
+// The audit fingerprint key is read from the environment, not to show up in process listings.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	policiesPath := fs.String("policies", "", "policy config file")
+	auditLog := fs.String("audit-log", "", "audit log file, fingerprints are keyed with $"+auditKeyEnv)
+	binsPath := fs.String("bins", "", "BIN data file, needed by country and funding rules")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := loadBINs(*binsPath)
+	if err != nil {
+		return err
+	}
+	if *auditLog != "" {
+		key := os.Getenv(auditKeyEnv)
+		if key == "" {
+			return fmt.Errorf("serve: an audit log needs a fingerprint key in $%s", auditKeyEnv)
+		}
+		sink, err := audit.NewFileSink(*auditLog)
+		if err != nil {
+			return err
+		}
+		defer sink.Close()
+		opts = append(opts, card.WithAuditSink(sink, []byte(key)))
+	}
+
+	policies := policy.Set{}
+	if *policiesPath != "" {
+		if policies, err = policy.LoadFile(*policiesPath); err != nil {
+			return err
+		}
+	}
+
+	return http.ListenAndServe(*addr, service.NewHandler(policies, opts...))
+}
+
+// runLintSchemes checks a scheme table file, or the built-in table without a file,
+// e.g. card lint-schemes schemes.json
+func runLintSchemes(args []string) error {
+	fs := flag.NewFlagSet("lint-schemes", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var issues []utils.LintIssue
+	switch fs.NArg() {
+	case 0:
+		issues = utils.LintDefaultSchemes()
+	case 1:
+		var err error
+		if issues, err = utils.LintLookupFile(fs.Arg(0)); err != nil {
+			return err
+		}
+	default:
+		return errors.New("lint-schemes: expected at most one scheme table file")
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("lint-schemes: %d issues found", len(issues))
+	}
+	return nil
+}
+
+// runVerifyAudit checks the hash chain of an audit log including its rotated files, e.g. card verify-audit audit.jsonl
+func runVerifyAudit(args []string) error {
+	fs := flag.NewFlagSet("verify-audit", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("verify-audit: expected the audit log file")
+	}
+
+	files, err := audit.Files(fs.Arg(0))
+	if err != nil {
+		return err
+	} else if len(files) == 0 {
+		return fmt.Errorf("verify-audit: %s not found", fs.Arg(0))
+	}
+
+	result, err := audit.Verify(files...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d records in %d files verified, sequence %d to %d, last hash %s\n",
+		result.Records, len(files), result.FirstSeq, result.LastSeq, result.LastHash)
+	return nil
+}
+
+// loadBINs returns the validator options classifying with the built-in table and the BIN data of the file,
+// none without a file.
+func loadBINs(path string) ([]card.Option, error) {
+	if path == "" {
+		return nil, nil
+	}
+	bins, _, err := utils.LoadBINFile(path, utils.DefaultLookup())
+	if err != nil {
+		return nil, err
+	}
+	return []card.Option{card.WithLookup(bins)}, nil
+}
+
+func loadPolicy(path, name string) (*policy.Policy, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if path == "" {
+		return nil, errors.New("a policy name needs a policy config file")
+	}
+
+	policies, err := policy.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return policies.Get(name)
+}
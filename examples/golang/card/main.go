@@ -3,14 +3,23 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"card/pkg/card"
 	"card/pkg/utils"
 )
 
 func main() {
-	exampleOne()
-	exampleTwo()
+	// Without a command the examples are played, as before.
+	if len(os.Args) < 2 {
+		exampleOne()
+		exampleTwo()
+		return
+	}
+
+	if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func exampleOne() {
@@ -0,0 +1,266 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"card/pkg/card"
+)
+
+// DefaultMaxSize is the size a log file is rotated at unless configured otherwise.
+const DefaultMaxSize = 64 << 20
+
+// entry is a line of the log. Hash covers the sequence number, the previous hash and the record
+// exactly as written, so removing, reordering or editing lines breaks the chain.
+type entry struct {
+	Seq    uint64          `json:"seq"`
+	Prev   string          `json:"prev"`
+	Hash   string          `json:"hash"`
+	Record json.RawMessage `json:"record"`
+}
+
+func chainHash(seq uint64, prev string, record []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n", seq, prev)
+	h.Write(record)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FileSink writes audit records as JSON Lines to path. Once the file exceeds the maximum size it is
+// renamed to path.<sequence number of its first record> and a new file is started; the chain
+// continues across files. It is safe for concurrent use.
+type FileSink struct {
+	path    string
+	maxSize int64
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	firstSeq uint64 // Sequence number of the first record in the current file.
+	seq      uint64
+	prev     string
+}
+
+type Option func(*FileSink)
+
+// WithMaxSize sets the size in bytes the file is rotated at.
+func WithMaxSize(size int64) Option {
+	return func(s *FileSink) {
+		s.maxSize = size
+	}
+}
+
+// NewFileSink opens or creates the log and continues the chain of its latest record.
+func NewFileSink(path string, opts ...Option) (*FileSink, error) {
+	s := &FileSink{path: path, maxSize: DefaultMaxSize}
+	for _, o := range opts {
+		o(s)
+	}
+
+	if err := s.resume(); err != nil {
+		return nil, err
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resume finds the last record, in the current file or, after a crash during rotation, in the latest rotated one.
+func (s *FileSink) resume() error {
+	if err := repairTornLine(s.path); err != nil {
+		return err
+	}
+	files, err := Files(s.path)
+	if err != nil {
+		return err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		last, first, ok, err := lastEntry(files[i])
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+		s.seq, s.prev = last.Seq, last.Hash
+		if files[i] == s.path {
+			s.firstSeq = first.Seq
+		} else {
+			s.firstSeq = last.Seq + 1
+		}
+		return nil
+	}
+	s.firstSeq = 1
+	return nil
+}
+
+// repairTornLine drops an incomplete last line left by a crash during a write. Its Audit call
+// did not return successfully, so the record was never considered durable.
+func repairTornLine(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+func lastEntry(path string) (entry, entry, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry{}, entry{}, false, err
+	}
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	if len(lines[0]) == 0 {
+		return entry{}, entry{}, false, nil
+	}
+
+	var first, last entry
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		return entry{}, entry{}, false, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := json.Unmarshal(lines[len(lines)-1], &last); err != nil {
+		return entry{}, entry{}, false, fmt.Errorf("%s: %w", path, err)
+	}
+	return last, first, true, nil
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// Audit appends the record and syncs the file, a record is durable once Audit returns.
+func (s *FileSink) Audit(record card.AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("audit: sink closed")
+	}
+
+	seq := s.seq + 1
+	hash := chainHash(seq, s.prev, data)
+	line, err := json.Marshal(entry{Seq: seq, Prev: s.prev, Hash: hash, Record: data})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+		s.firstSeq = seq
+	}
+
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.size += int64(len(line))
+	s.seq, s.prev = seq, hash
+	return nil
+}
+
+// rotate keeps the current file open until its successor is, so a failed rotation leaves the sink
+// writing to the current file and the next Audit tries again.
+func (s *FileSink) rotate() error {
+	rotated := rotatedPath(s.path, s.firstSeq)
+	if err := os.Rename(s.path, rotated); err != nil {
+		return err
+	}
+	previous := s.file
+	if err := s.open(); err != nil {
+		if renameErr := os.Rename(rotated, s.path); renameErr != nil {
+			return errors.Join(err, renameErr)
+		}
+		return err
+	}
+	// Every record was synced when written, closing cannot lose any.
+	_ = previous.Close()
+	return nil
+}
+
+// rotatedPath pads the sequence number, so rotated files sort in chain order.
+func rotatedPath(path string, firstSeq uint64) string {
+	return fmt.Sprintf("%s.%012d", path, firstSeq)
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Files returns the rotated files of the log followed by the current one, in chain order.
+// Files that do not exist are left out.
+func Files(path string) ([]string, error) {
+	rotated, err := filepath.Glob(path + ".[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(rotated)
+
+	if _, err := os.Stat(path); err == nil {
+		rotated = append(rotated, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// scanLines calls fn for every line of the file with its 1-based line number.
+func scanLines(path string, fn func(n int, line []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if err := fn(n, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+var _ card.AuditSink = &FileSink{}
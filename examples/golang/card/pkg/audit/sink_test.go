@@ -0,0 +1,226 @@
+//go:build unit
+
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"card/pkg"
+	"card/pkg/card"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRecord(i int) card.AuditRecord {
+	return card.AuditRecord{
+		Time:      time.Date(2024, 6, 25, 12, 0, i, 0, time.UTC),
+		Caller:    "checkout",
+		MaskedPAN: "401288******1881",
+		Schema:    pkg.SchemaVisa,
+		Valid:     true,
+	}
+}
+
+func writeRecords(t *testing.T, sink *FileSink, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		assert.NoError(t, sink.Audit(testRecord(i)))
+	}
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, WithMaxSize(1024))
+	assert.NoError(t, err)
+	writeRecords(t, sink, 0, 20)
+	assert.NoError(t, sink.Close())
+
+	files, err := Files(path)
+	assert.NoError(t, err)
+	assert.Greater(t, len(files), 2)
+	assert.Equal(t, path+".000000000001", files[0])
+	assert.Equal(t, path, files[len(files)-1])
+	for _, file := range files {
+		info, err := os.Stat(file)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1024))
+	}
+
+	result, err := Verify(files...)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, result.Records)
+	assert.Equal(t, uint64(1), result.FirstSeq)
+	assert.Equal(t, uint64(20), result.LastSeq)
+}
+
+func TestFileSinkKeepsWritingAfterFailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, WithMaxSize(1024))
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	// A non-empty directory under the rotated name makes the rename fail.
+	blocker := path + ".000000000001"
+	assert.NoError(t, os.MkdirAll(filepath.Join(blocker, "dir"), 0o700))
+	for i := 0; sink.Audit(testRecord(i)) == nil; i++ {
+		assert.Less(t, i, 20, "rotation never failed")
+	}
+
+	assert.NoError(t, os.RemoveAll(blocker))
+	writeRecords(t, sink, 100, 110)
+
+	files, err := Files(path)
+	assert.NoError(t, err)
+	result, err := Verify(files...)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), result.FirstSeq)
+	assert.Equal(t, uint64(result.Records), result.LastSeq)
+}
+
+func TestFileSinkResumesChain(t *testing.T) {
+	cases := []struct {
+		name   string
+		damage func(path string)
+	}{
+		{
+			"should-resume-from-current-file",
+			func(string) {},
+		},
+		{
+			"should-resume-from-rotated-file-after-crash-during-rotation",
+			func(path string) {
+				assert.NoError(t, os.Rename(path, rotatedPath(path, 4)))
+			},
+		},
+		{
+			"should-drop-torn-last-line-after-crash-during-write",
+			func(path string) {
+				file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+				assert.NoError(t, err)
+				_, err = file.WriteString(`{"seq":6,"prev":"`)
+				assert.NoError(t, err)
+				assert.NoError(t, file.Close())
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			sink, err := NewFileSink(path, WithMaxSize(1024))
+			assert.NoError(t, err)
+			writeRecords(t, sink, 0, 5)
+			assert.NoError(t, sink.Close())
+			c.damage(path)
+
+			sink, err = NewFileSink(path, WithMaxSize(1024))
+			assert.NoError(t, err)
+			writeRecords(t, sink, 5, 10)
+			assert.NoError(t, sink.Close())
+
+			files, err := Files(path)
+			assert.NoError(t, err)
+			result, err := Verify(files...)
+			assert.NoError(t, err)
+			assert.Equal(t, 10, result.Records)
+		})
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	cases := []struct {
+		name   string
+		tamper func(lines [][]byte) [][]byte
+	}{
+		{
+			"should-detect-modified-record",
+			func(lines [][]byte) [][]byte {
+				lines[2] = bytes.Replace(lines[2], []byte(`"valid":true`), []byte(`"valid":false`), 1)
+				return lines
+			},
+		},
+		{
+			"should-detect-removed-record",
+			func(lines [][]byte) [][]byte {
+				return append(lines[:2], lines[3:]...)
+			},
+		},
+		{
+			"should-detect-reordered-records",
+			func(lines [][]byte) [][]byte {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+		},
+		{
+			"should-detect-malformed-line",
+			func(lines [][]byte) [][]byte {
+				lines[3] = []byte("{")
+				return lines
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			sink, err := NewFileSink(path)
+			assert.NoError(t, err)
+			writeRecords(t, sink, 0, 5)
+			assert.NoError(t, sink.Close())
+
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			lines := c.tamper(bytes.Split(bytes.TrimSpace(data), []byte("\n")))
+			assert.NoError(t, os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0o600))
+
+			_, err = Verify(path)
+			assert.ErrorIs(t, err, ErrBrokenChain)
+		})
+	}
+}
+
+func TestVerifyDetectsMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, WithMaxSize(512))
+	assert.NoError(t, err)
+	writeRecords(t, sink, 0, 20)
+	assert.NoError(t, sink.Close())
+
+	files, err := Files(path)
+	assert.NoError(t, err)
+	assert.Greater(t, len(files), 2)
+
+	// Dropping the oldest file is retention, dropping one in the middle breaks the chain.
+	_, err = Verify(files[1:]...)
+	assert.NoError(t, err)
+	_, err = Verify(append(files[:1:1], files[2:]...)...)
+	assert.ErrorIs(t, err, ErrBrokenChain)
+}
+
+func TestFileSinkWithValidator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	validator := card.NewValidator(card.WithAuditSink(sink, []byte("key")), card.WithCaller("checkout"))
+	_, err = validator.NewCreditCard("4012 8888 8888 1881")
+	assert.NoError(t, err)
+	_, err = validator.NewCreditCard("4012-8888")
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "4012888888881881")
+	assert.Contains(t, string(data), `"maskedPan":"401288******1881"`)
+	assert.Contains(t, string(data), `"errorKind":"non-digit"`)
+
+	result, err := Verify(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Records)
+}
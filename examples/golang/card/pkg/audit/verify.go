@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrBrokenChain = errors.New("audit: hash chain broken")
+
+// VerifyResult summarizes a verified chain.
+type VerifyResult struct {
+	Records  int
+	FirstSeq uint64 // Greater than 1 when older files were removed, e.g. by retention.
+	LastSeq  uint64
+	LastHash string // Anchor it elsewhere to detect records cut off at the end.
+}
+
+// Verify checks the chain across the files, which must be given in chain order, see Files.
+// The first record may continue a chain whose older files are gone; from there on every record
+// must follow its predecessor and hash to the stored value.
+func Verify(paths ...string) (VerifyResult, error) {
+	var result VerifyResult
+	for _, path := range paths {
+		err := scanLines(path, func(n int, line []byte) error {
+			var e entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return fmt.Errorf("%w: %s:%d: %v", ErrBrokenChain, path, n, err)
+			}
+
+			if result.Records == 0 {
+				result.FirstSeq = e.Seq
+			} else if e.Seq != result.LastSeq+1 {
+				return fmt.Errorf("%w: %s:%d: sequence %d follows %d", ErrBrokenChain, path, n, e.Seq, result.LastSeq)
+			} else if e.Prev != result.LastHash {
+				return fmt.Errorf("%w: %s:%d: previous hash does not match record %d", ErrBrokenChain, path, n, result.LastSeq)
+			}
+			if chainHash(e.Seq, e.Prev, e.Record) != e.Hash {
+				return fmt.Errorf("%w: %s:%d: record %d was modified", ErrBrokenChain, path, n, e.Seq)
+			}
+
+			result.Records++
+			result.LastSeq, result.LastHash = e.Seq, e.Hash
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,17 @@
+package pkg
+
+type Funding string
+
+const (
+	FundingCredit  Funding = "credit"
+	FundingDebit   Funding = "debit"
+	FundingPrepaid Funding = "prepaid"
+)
+
+// BINInfo holds issuer data known for a card's bank identification number.
+type BINInfo struct {
+	Country   string   // ISO 3166-1 alpha-2 code of the issuing country.
+	Funding   Funding  // Funding source of the card.
+	Networks  []Schema // Networks the card is co-badged with besides its scheme, e.g. a domestic debit network.
+	Regulated bool     // The issuer falls under debit interchange regulation.
+}
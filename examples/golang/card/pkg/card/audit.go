@@ -0,0 +1,101 @@
+package card
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"card/pkg"
+	"card/pkg/utils"
+)
+
+// minUnmaskedLength is the shortest number whose first six and last four digits are shown,
+// shorter ones would be mostly disclosed.
+const minUnmaskedLength = 13
+
+// ErrAudit wraps failures of the audit sink, the validation itself did not fail.
+var ErrAudit = errors.New("audit failed")
+
+// AuditRecord describes a single validation. It carries no clear PAN.
+type AuditRecord struct {
+	Time        time.Time  `json:"time"`
+	Caller      string     `json:"caller,omitempty"`
+	MaskedPAN   string     `json:"maskedPan"`
+	Fingerprint string     `json:"fingerprint,omitempty"` // Keyed like Fingerprint, empty for empty input.
+	Schema      pkg.Schema `json:"schema,omitempty"`
+	Valid       bool       `json:"valid"`
+	ErrorKind   string     `json:"errorKind,omitempty"`
+}
+
+// AuditSink receives a record of every validation of a Validator.
+// It must be safe for concurrent use when the validator is shared.
+type AuditSink interface {
+	Audit(AuditRecord) error
+}
+
+// WithAuditSink records every validation in the sink. Fingerprints are keyed with fingerprintKey.
+// Validation fails when the sink fails, no validation goes unrecorded.
+func WithAuditSink(sink AuditSink, fingerprintKey []byte) Option {
+	return func(v *Validator) {
+		v.audit = sink
+		v.auditKey = fingerprintKey
+	}
+}
+
+// WithCaller names the service or client the validator validates for, in audit records.
+func WithCaller(caller string) Option {
+	return func(v *Validator) {
+		v.caller = caller
+	}
+}
+
+// ForCaller returns a copy of the validator auditing for the caller, e.g. the client of a single request.
+// The copy shares the lookup and audit sink.
+func (v *Validator) ForCaller(caller string) *Validator {
+	c := *v
+	c.caller = caller
+	return &c
+}
+
+func (v *Validator) auditValidation(number string, c CreditCard, validationErr error) error {
+	record := AuditRecord{
+		Time:      v.clock(),
+		Caller:    v.caller,
+		MaskedPAN: maskPAN(number),
+		ErrorKind: errorKind(validationErr),
+	}
+	if number != "" {
+		record.Fingerprint = fingerprint(v.auditKey, number)
+	}
+	if c != nil {
+		record.Schema = c.Schema()
+		record.Valid = c.Valid()
+	}
+	return v.audit.Audit(record)
+}
+
+// maskPAN keeps the first six and last four digits, as PCI DSS allows.
+func maskPAN(number string) string {
+	if len(number) < minUnmaskedLength {
+		return strings.Repeat("*", len(number))
+	}
+	return number[:6] + strings.Repeat("*", len(number)-10) + number[len(number)-4:]
+}
+
+// errorKind names validation errors in audit records, messages may change between versions.
+func errorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, utils.ErrEmpty):
+		return "empty"
+	case errors.Is(err, utils.ErrTooLong):
+		return "too-long"
+	case errors.Is(err, utils.ErrNonDigit):
+		return "non-digit"
+	case errors.Is(err, ErrChecksum):
+		return "checksum"
+	default:
+		return "other"
+	}
+}
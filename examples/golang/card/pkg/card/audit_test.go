@@ -0,0 +1,121 @@
+//go:build unit
+
+package card
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	records []AuditRecord
+	err     error
+}
+
+func (rs *recordingSink) Audit(record AuditRecord) error {
+	rs.records = append(rs.records, record)
+	return rs.err
+}
+
+func TestAuditSink(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	key := []byte("key")
+	visa, err := NewCreditCard("4012888888881881")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name           string
+		opts           []Option
+		cardNumber     string
+		expectedRecord AuditRecord
+	}{
+		{
+			"should-record-valid-card",
+			nil,
+			"4012 8888 8888 1881",
+			AuditRecord{
+				Time:        now,
+				Caller:      "checkout",
+				MaskedPAN:   "401288******1881",
+				Fingerprint: Fingerprint(key, visa),
+				Schema:      pkg.SchemaVisa,
+				Valid:       true,
+			},
+		},
+		{
+			"should-record-checksum-error-in-strict-mode",
+			[]Option{WithChecksumPolicy(ChecksumStrict)},
+			"4012888888881882",
+			AuditRecord{
+				Time:        now,
+				Caller:      "checkout",
+				MaskedPAN:   "401288******1882",
+				Fingerprint: fingerprint(key, "4012888888881882"),
+				ErrorKind:   "checksum",
+			},
+		},
+		{
+			"should-mask-short-input-completely",
+			nil,
+			"1234-5678",
+			AuditRecord{
+				Time:        now,
+				Caller:      "checkout",
+				MaskedPAN:   "*********",
+				Fingerprint: fingerprint(key, "1234-5678"),
+				ErrorKind:   "non-digit",
+			},
+		},
+		{
+			"should-record-empty-input-without-fingerprint",
+			nil,
+			"  ",
+			AuditRecord{
+				Time:      now,
+				Caller:    "checkout",
+				ErrorKind: "empty",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			opts := append([]Option{
+				WithAuditSink(sink, key),
+				WithCaller("checkout"),
+				WithClock(func() time.Time { return now }),
+			}, c.opts...)
+
+			_, _ = NewCreditCard(c.cardNumber, opts...)
+			assert.Equal(t, []AuditRecord{c.expectedRecord}, sink.records)
+		})
+	}
+}
+
+func TestAuditSinkFailureFailsValidation(t *testing.T) {
+	sink := &recordingSink{err: errors.New("disk full")}
+	creditCard, err := NewCreditCard("4012888888881881", WithAuditSink(sink, nil))
+	assert.ErrorIs(t, err, ErrAudit)
+	assert.EqualError(t, err, "audit failed: disk full")
+	assert.Nil(t, creditCard)
+}
+
+func TestValidatorForCaller(t *testing.T) {
+	sink := &recordingSink{}
+	validator := NewValidator(WithAuditSink(sink, nil), WithCaller("checkout"))
+
+	_, err := validator.ForCaller("192.0.2.1").NewCreditCard("4012888888881881")
+	assert.NoError(t, err)
+	_, err = validator.NewCreditCard("4012888888881881")
+	assert.NoError(t, err)
+
+	assert.Len(t, sink.records, 2)
+	assert.Equal(t, "192.0.2.1", sink.records[0].Caller)
+	assert.Equal(t, "checkout", sink.records[1].Caller)
+}
@@ -2,9 +2,9 @@ package card
 
 import (
 	"fmt"
+	"time"
 
 	"card/pkg"
-	"card/pkg/utils"
 )
 
 // CreditCard interface defines the methods for validating and displaying card information
@@ -14,26 +14,29 @@ type CreditCard interface {
 	Schema() pkg.Schema
 }
 
-type card struct {
-	number string     // The sanitized card number.
-	valid  bool       // Cached result indicating if the card is valid
-	schema pkg.Schema // The card's schema determined during validation.
+// Validated is implemented by cards which know when they were validated,
+// like the ones created by a Validator.
+type Validated interface {
+	ValidatedAt() time.Time
 }
 
-// NewCreditCard creates a new credit card instance from a string representation of the card number
-func NewCreditCard(cardNumber string) (CreditCard, error) {
-	number := utils.NormalizeCardNumber(cardNumber)
+type card struct {
+	number      string     // The sanitized card number.
+	valid       bool       // Cached result indicating if the card is valid
+	schema      pkg.Schema // The card's schema determined during validation.
+	validatedAt time.Time  // Time of validation, taken from the validator's clock.
+}
 
-	valid, schema, err := validateCardDetails(number)
-	if err != nil {
-		return nil, err
-	}
+// NewCreditCard creates a new credit card instance from a string representation of the card number.
+// Without options the built-in scheme table and normalization are used and failing checksums are reported by Valid.
+func NewCreditCard(cardNumber string, opts ...Option) (CreditCard, error) {
+	return NewValidator(opts...).NewCreditCard(cardNumber)
+}
 
-	return &card{
-		number: number,
-		valid:  valid,
-		schema: schema,
-	}, nil
+// NewCreditCardAt creates a credit card classified with the scheme ranges effective at the given time,
+// e.g. the date of a historical transaction.
+func NewCreditCardAt(cardNumber string, at time.Time, opts ...Option) (CreditCard, error) {
+	return NewValidator(opts...).NewCreditCardAt(cardNumber, at)
 }
 
 // Number returns the sanitized (normalized) card number.
@@ -51,19 +54,12 @@ func (c *card) Schema() pkg.Schema {
 	return c.schema
 }
 
+// ValidatedAt returns when the card was validated.
+func (c *card) ValidatedAt() time.Time {
+	return c.validatedAt
+}
+
 func (c *card) String() string {
 	validText := map[bool]string{true: "valid", false: "invalid"}[c.valid]
 	return fmt.Sprintf("The card '%s' is '%s' and has '%s' schema", c.number, validText, c.schema)
 }
-
-func validateCardDetails(cardNumber string) (bool, pkg.Schema, error) {
-	valid, err := utils.CardValid(cardNumber)
-	if err != nil {
-		return false, "", err
-	}
-	schema, err := utils.CardSchema(cardNumber)
-	if err != nil {
-		return false, "", err
-	}
-	return valid, schema, nil
-}
@@ -0,0 +1,20 @@
+package card
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint returns a keyed hash (HMAC-SHA256, hex encoded) of the card number.
+// It identifies a card across requests without keeping the clear PAN;
+// the key must stay secret, as PANs are easily enumerated otherwise.
+func Fingerprint(key []byte, c CreditCard) string {
+	return fingerprint(key, c.Number())
+}
+
+func fingerprint(key []byte, number string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(number))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,24 @@
+//go:build unit
+
+package card
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	first, err := NewCreditCard("4012 8888 8888 1881")
+	assert.NoError(t, err)
+	second, err := NewCreditCard("4012888888881881")
+	assert.NoError(t, err)
+	other, err := NewCreditCard("5105105105105100")
+	assert.NoError(t, err)
+
+	key := []byte("secret")
+	assert.Len(t, Fingerprint(key, first), 64)
+	assert.Equal(t, Fingerprint(key, first), Fingerprint(key, second))
+	assert.NotEqual(t, Fingerprint(key, first), Fingerprint(key, other))
+	assert.NotEqual(t, Fingerprint(key, first), Fingerprint([]byte("other"), first))
+}
@@ -0,0 +1,172 @@
+package card
+
+import "card/pkg/utils"
+
+// RiskFlag marks why a card number should not be trusted in production.
+// It is independent from Valid(): most flagged numbers pass the checksum on purpose.
+type RiskFlag uint8
+
+const (
+	// RiskTestCard marks a publicly documented network test card.
+	RiskTestCard RiskFlag = 1 << iota
+	// RiskSandboxOnly marks a number from a range reserved for sandbox environments.
+	RiskSandboxOnly
+	// RiskSuspiciousPattern marks repeated, sequential or low diversity digits.
+	RiskSuspiciousPattern
+)
+
+type Environment int
+
+const (
+	EnvironmentProduction Environment = iota
+	EnvironmentSandbox
+)
+
+const (
+	// iinLength digits identify the issuer, patterns are looked for in the account part only.
+	iinLength = 6
+	// minPatternRun is the shortest run of repeated or sequential digits treated as suspicious.
+	minPatternRun = 6
+	// maxLowDiversity is the highest count of distinct account digits treated as suspicious,
+	// checked from minDiversityLength digits on since short account parts hit it by chance.
+	maxLowDiversity    = 2
+	minDiversityLength = 8
+)
+
+// Risk is the outcome of a risk assessment.
+type Risk struct {
+	Flags   RiskFlag
+	Reasons []string
+}
+
+// Has reports whether the flag is set.
+func (r Risk) Has(flag RiskFlag) bool {
+	return r.Flags&flag != 0
+}
+
+// Allowed reports whether the card may be used in the environment.
+// Sandbox environments accept flagged numbers, production accepts unflagged ones only.
+func (r Risk) Allowed(env Environment) bool {
+	return env == EnvironmentSandbox || r.Flags == 0
+}
+
+func (r *Risk) add(flag RiskFlag, reason string) {
+	r.Flags |= flag
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// DefaultTestCards returns well-known test numbers published by networks and payment providers.
+func DefaultTestCards() []string {
+	return []string{
+		// Visa
+		"4111111111111111", "4012888888881881", "4222222222222", "4242424242424242", "4000056655665556",
+		// MasterCard
+		"5555555555554444", "5105105105105100", "2223003122003222", "5200828282828210",
+		// American Express
+		"378282246310005", "371449635398431", "378734493671000",
+		// JCB
+		"3530111333300000", "3566002020360505",
+		// Maestro
+		"6759649826438453", "6799990100000000019",
+		// Others which may reach a checkout although they have no schema here
+		"6011111111111117", "6011000990139424", "30569309025904", "38520000023237",
+	}
+}
+
+type RiskChecker struct {
+	testCards       map[string]struct{}
+	sandboxPrefixes []string
+}
+
+type RiskOption func(*RiskChecker)
+
+// WithTestCards replaces the default test card list.
+func WithTestCards(numbers ...string) RiskOption {
+	return func(rc *RiskChecker) {
+		rc.testCards = make(map[string]struct{}, len(numbers))
+		for _, number := range numbers {
+			rc.testCards[utils.NormalizeCardNumber(number)] = struct{}{}
+		}
+	}
+}
+
+// WithSandboxPrefixes sets the prefixes or prefix ranges ("999999", "400000-400009")
+// issued for sandbox use only.
+func WithSandboxPrefixes(prefixes ...string) RiskOption {
+	return func(rc *RiskChecker) {
+		rc.sandboxPrefixes = prefixes
+	}
+}
+
+// NewRiskChecker creates a checker using DefaultTestCards unless configured otherwise.
+// The checker is read-only after creation and safe for concurrent use.
+func NewRiskChecker(opts ...RiskOption) *RiskChecker {
+	rc := &RiskChecker{}
+	WithTestCards(DefaultTestCards()...)(rc)
+	for _, o := range opts {
+		o(rc)
+	}
+	return rc
+}
+
+// Assess flags test cards, sandbox-only numbers and suspicious digit patterns.
+func (rc *RiskChecker) Assess(c CreditCard) Risk {
+	var risk Risk
+	number := c.Number()
+
+	if _, ok := rc.testCards[number]; ok {
+		risk.add(RiskTestCard, "known network test card")
+	}
+
+	for _, prefix := range rc.sandboxPrefixes {
+		if ok, err := utils.MatchPrefix(number, prefix); err == nil && ok {
+			risk.add(RiskSandboxOnly, "sandbox-only range "+prefix)
+			break
+		}
+	}
+
+	if reason, ok := suspiciousPattern(number); ok {
+		risk.add(RiskSuspiciousPattern, reason)
+	}
+
+	return risk
+}
+
+// suspiciousPattern inspects the account digits, between the issuer identification and the check digit,
+// which are random for genuinely issued cards.
+func suspiciousPattern(number string) (string, bool) {
+	if len(number) <= iinLength+1 {
+		return "", false
+	}
+	account := number[iinLength : len(number)-1]
+
+	repeated, ascending, descending := 1, 1, 1
+	seen := map[byte]struct{}{account[0]: {}}
+	for i := 1; i < len(account); i++ {
+		prev, cur := account[i-1], account[i]
+		seen[cur] = struct{}{}
+
+		repeated = nextRun(repeated, cur == prev)
+		ascending = nextRun(ascending, cur == prev+1 || (prev == '9' && cur == '0'))
+		descending = nextRun(descending, cur+1 == prev || (prev == '0' && cur == '9'))
+
+		switch {
+		case repeated >= minPatternRun:
+			return "repeated digits", true
+		case ascending >= minPatternRun || descending >= minPatternRun:
+			return "sequential digits", true
+		}
+	}
+
+	if len(account) >= minDiversityLength && len(seen) <= maxLowDiversity {
+		return "low digit diversity", true
+	}
+	return "", false
+}
+
+func nextRun(run int, continues bool) int {
+	if continues {
+		return run + 1
+	}
+	return 1
+}
@@ -0,0 +1,95 @@
+//go:build unit
+
+package card
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRiskCheckerAssess(t *testing.T) {
+	checker := NewRiskChecker(WithSandboxPrefixes("999999", "400000-400009"))
+
+	cases := []struct {
+		name            string
+		cardNumber      string
+		expectedFlags   RiskFlag
+		expectedReasons []string
+	}{
+		{
+			"should-flag-test-card-with-repeated-digits",
+			"4111 1111 1111 1111",
+			RiskTestCard | RiskSuspiciousPattern,
+			[]string{"known network test card", "repeated digits"},
+		},
+		{
+			"should-flag-test-card",
+			"5555555555554444",
+			RiskTestCard | RiskSuspiciousPattern,
+			[]string{"known network test card", "repeated digits"},
+		},
+		{
+			"should-flag-sandbox-range",
+			"4000051234567890",
+			RiskSandboxOnly | RiskSuspiciousPattern,
+			[]string{"sandbox-only range 400000-400009", "sequential digits"},
+		},
+		{
+			"should-flag-descending-digits",
+			"4929107654321098",
+			RiskSuspiciousPattern,
+			[]string{"sequential digits"},
+		},
+		{
+			"should-flag-low-diversity",
+			"4929101212121216",
+			RiskSuspiciousPattern,
+			[]string{"low digit diversity"},
+		},
+		{
+			"should-not-flag-regular-number",
+			"4929103870215526",
+			0,
+			nil,
+		},
+		{
+			"should-not-flag-short-number",
+			"4929",
+			0,
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			creditCard, err := NewCreditCard(c.cardNumber)
+			assert.NoError(t, err)
+			risk := checker.Assess(creditCard)
+			assert.Equal(t, c.expectedFlags, risk.Flags)
+			assert.Equal(t, c.expectedReasons, risk.Reasons)
+		})
+	}
+}
+
+func TestRiskCheckerCustomTestCards(t *testing.T) {
+	checker := NewRiskChecker(WithTestCards("4929 1038 7021 5526"))
+
+	creditCard, err := NewCreditCard("4929103870215526")
+	assert.NoError(t, err)
+	assert.True(t, checker.Assess(creditCard).Has(RiskTestCard))
+
+	creditCard, err = NewCreditCard("378282246310005")
+	assert.NoError(t, err)
+	assert.False(t, checker.Assess(creditCard).Has(RiskTestCard))
+}
+
+func TestRiskAllowed(t *testing.T) {
+	flagged := Risk{Flags: RiskTestCard}
+	assert.False(t, flagged.Allowed(EnvironmentProduction))
+	assert.True(t, flagged.Allowed(EnvironmentSandbox))
+
+	clean := Risk{}
+	assert.True(t, clean.Allowed(EnvironmentProduction))
+	assert.True(t, clean.Allowed(EnvironmentSandbox))
+}
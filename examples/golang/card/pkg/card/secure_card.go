@@ -0,0 +1,152 @@
+package card
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"card/pkg"
+	"card/pkg/utils"
+)
+
+var ErrWiped = errors.New("card: secure card wiped")
+
+// SecureCard keeps the card number in a byte slice it owns and zeroes on Wipe, unlike CreditCard
+// whose string stays in memory until garbage collected and may end up in core dumps.
+// Validation runs on the slice directly, no string copy of the number is ever made.
+// It is safe for concurrent use.
+type SecureCard struct {
+	mu          sync.Mutex
+	number      []byte // Normalized digits, nil once wiped.
+	valid       bool
+	schema      pkg.Schema
+	validatedAt time.Time
+}
+
+// NewSecureCard validates and classifies the number with the built-in scheme table and the ranges
+// effective now, see Validator.NewSecureCard.
+// It takes ownership of the slice: the number is normalized in place, and the slice is zeroed
+// on Wipe or when validation fails. Callers must not keep other copies of it.
+func NewSecureCard(number []byte) (*SecureCard, error) {
+	return newSecureCard(number, time.Now())
+}
+
+// NewSecureCard is NewSecureCard with the validation time taken from the validator's clock.
+// The lookup, normalizer and checksum policy of the validator are not used, as they work on strings.
+func (v *Validator) NewSecureCard(number []byte) (*SecureCard, error) {
+	return newSecureCard(number, v.clock())
+}
+
+func newSecureCard(number []byte, now time.Time) (*SecureCard, error) {
+	analysis, err := utils.AnalyzeAt(number, now)
+	if err != nil {
+		clear(number)
+		return nil, err
+	}
+
+	// Analysis succeeded, so everything but digits is whitespace. Compacting the digits
+	// to the front avoids a second buffer holding the number.
+	n := 0
+	for _, ch := range number {
+		if ch >= '0' && ch <= '9' {
+			number[n] = ch
+			n++
+		}
+	}
+	clear(number[n:])
+
+	return &SecureCard{
+		number:      number[:n],
+		valid:       analysis.Valid,
+		schema:      analysis.Schema,
+		validatedAt: now,
+	}, nil
+}
+
+// Use calls fn with the normalized number, which fn must neither modify nor retain.
+func (c *SecureCard) Use(fn func(number []byte) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.number == nil {
+		return ErrWiped
+	}
+	return fn(c.number)
+}
+
+func (c *SecureCard) Valid() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.number == nil {
+		return false, ErrWiped
+	}
+	return c.valid, nil
+}
+
+func (c *SecureCard) Schema() (pkg.Schema, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.number == nil {
+		return pkg.SchemaUnknown, ErrWiped
+	}
+	return c.schema, nil
+}
+
+// ValidatedAt returns when the card was validated.
+func (c *SecureCard) ValidatedAt() (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.number == nil {
+		return time.Time{}, ErrWiped
+	}
+	return c.validatedAt, nil
+}
+
+// Last4 returns the last four digits, which may be displayed and stored.
+func (c *SecureCard) Last4() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.number == nil {
+		return "", ErrWiped
+	}
+	return string(c.number[max(0, len(c.number)-4):]), nil
+}
+
+// Wipe zeroes the number. It is idempotent.
+func (c *SecureCard) Wipe() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.number)
+	c.number = nil
+	c.schema = pkg.SchemaUnknown
+	c.validatedAt = time.Time{}
+}
+
+// Close wipes the card, so it can be released with defer like other resources.
+func (c *SecureCard) Close() error {
+	c.Wipe()
+	return nil
+}
+
+// String masks all but the last four digits, the number never reaches logs.
+func (c *SecureCard) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.number == nil {
+		return "wiped card"
+	}
+	visible := max(0, len(c.number)-4)
+	return "card " + strings.Repeat("*", visible) + string(c.number[visible:])
+}
+
+// GoString keeps %#v from printing the buffer.
+func (c *SecureCard) GoString() string {
+	return c.String()
+}
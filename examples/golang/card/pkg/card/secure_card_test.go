@@ -0,0 +1,158 @@
+//go:build unit
+
+package card
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"card/pkg"
+	"card/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecureCard(t *testing.T) {
+	cases := []struct {
+		name           string
+		cardNumber     string
+		expectedNumber string
+		expectedValid  bool
+		expectedSchema pkg.Schema
+		expectedError  error
+	}{
+		{
+			"should-be-valid-jcb",
+			" 3530 111 3333 00 000  ",
+			"3530111333300000",
+			true,
+			pkg.SchemaJCB,
+			nil,
+		},
+		{
+			"should-trim-no-break-spaces",
+			"\u00a05105 1051 0510 5100\u00a0",
+			"5105105105105100",
+			true,
+			pkg.SchemaMasterCard,
+			nil,
+		},
+		{
+			"should-be-invalid-but-recognized-maestro",
+			"6759649826438454",
+			"6759649826438454",
+			false,
+			pkg.SchemaMaestro,
+			nil,
+		},
+		{
+			"should-return-error-when-empty",
+			"   ",
+			"",
+			false,
+			"",
+			utils.ErrEmpty,
+		},
+		{
+			"should-return-error-for-non-digits",
+			"5105-1051-0510-5100",
+			"",
+			false,
+			"",
+			utils.ErrNonDigit,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buffer := []byte(c.cardNumber)
+			secureCard, err := NewSecureCard(buffer)
+			if c.expectedError != nil {
+				assert.ErrorIs(t, err, c.expectedError)
+				assert.Equal(t, make([]byte, len(buffer)), buffer, "buffer must be wiped on failure")
+				return
+			}
+			assert.NoError(t, err)
+
+			err = secureCard.Use(func(number []byte) error {
+				assert.Equal(t, c.expectedNumber, string(number))
+				return nil
+			})
+			assert.NoError(t, err)
+			valid, err := secureCard.Valid()
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedValid, valid)
+			schema, err := secureCard.Schema()
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedSchema, schema)
+		})
+	}
+}
+
+func TestSecureCardWipe(t *testing.T) {
+	buffer := []byte("4012 8888 8888 1881")
+	secureCard, err := NewSecureCard(buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "card ************1881", secureCard.String())
+
+	assert.NoError(t, secureCard.Close())
+	assert.Equal(t, make([]byte, len(buffer)), buffer)
+
+	_, err = secureCard.Valid()
+	assert.ErrorIs(t, err, ErrWiped)
+	_, err = secureCard.Schema()
+	assert.ErrorIs(t, err, ErrWiped)
+	_, err = secureCard.ValidatedAt()
+	assert.ErrorIs(t, err, ErrWiped)
+	_, err = secureCard.Last4()
+	assert.ErrorIs(t, err, ErrWiped)
+	err = secureCard.Use(func([]byte) error { return nil })
+	assert.ErrorIs(t, err, ErrWiped)
+	assert.Equal(t, "wiped card", secureCard.String())
+
+	// Wiping twice is harmless.
+	secureCard.Wipe()
+}
+
+func TestNewSecureCardWithClock(t *testing.T) {
+	now := time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC)
+	validator := NewValidator(WithClock(func() time.Time { return now }))
+	secureCard, err := validator.NewSecureCard([]byte("2221000000000009"))
+	assert.NoError(t, err)
+
+	validatedAt, err := secureCard.ValidatedAt()
+	assert.NoError(t, err)
+	assert.Equal(t, now, validatedAt)
+	// The 2-series was not issued before 2017.
+	schema, err := secureCard.Schema()
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, schema)
+}
+
+func TestSecureCardDoesNotPrintNumber(t *testing.T) {
+	secureCard, err := NewSecureCard([]byte("5105105105105100"))
+	assert.NoError(t, err)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		assert.NotContains(t, fmt.Sprintf(format, secureCard), "5105105105", format)
+	}
+}
+
+func TestNewSecureCardAllocations(t *testing.T) {
+	input := []byte("4012 8888 8888 1881")
+	buffer := make([]byte, len(input))
+	allocs := testing.AllocsPerRun(100, func() {
+		copy(buffer, input)
+		_, _ = NewSecureCard(buffer)
+	})
+	// Only the SecureCard itself, the number is never copied into a string.
+	assert.LessOrEqual(t, allocs, 1.0)
+
+	validator := NewValidator()
+	allocs = testing.AllocsPerRun(100, func() {
+		copy(buffer, input)
+		_, _ = validator.NewSecureCard(buffer)
+	})
+	assert.LessOrEqual(t, allocs, 1.0)
+}
@@ -0,0 +1,140 @@
+package card
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"card/pkg"
+	"card/pkg/utils"
+)
+
+// ChecksumPolicy decides what happens to numbers failing the checksum.
+type ChecksumPolicy int
+
+const (
+	// ChecksumReport accepts the number and reports the failure through Valid().
+	ChecksumReport ChecksumPolicy = iota
+	// ChecksumStrict rejects the number with ErrChecksum.
+	ChecksumStrict
+)
+
+var ErrChecksum = errors.New("invalid card number: checksum mismatch")
+
+// Validator creates credit cards with a fixed configuration.
+// It is immutable once built and safe for concurrent use, as long as the configured lookup is.
+type Validator struct {
+	lookup    utils.CardLookup
+	normalize func(string) string
+	checksum  ChecksumPolicy
+	clock     func() time.Time
+	audit     AuditSink
+	auditKey  []byte
+	caller    string
+}
+
+type Option func(*Validator)
+
+// WithLookup replaces the built-in scheme table, e.g. with a BIN database or a test double.
+// A nil lookup is ignored.
+func WithLookup(lookup utils.CardLookup) Option {
+	return func(v *Validator) {
+		if lookup != nil {
+			v.lookup = lookup
+		}
+	}
+}
+
+// WithNormalizer replaces utils.NormalizeCardNumber. A nil normalizer is ignored.
+func WithNormalizer(normalize func(string) string) Option {
+	return func(v *Validator) {
+		if normalize != nil {
+			v.normalize = normalize
+		}
+	}
+}
+
+func WithChecksumPolicy(policy ChecksumPolicy) Option {
+	return func(v *Validator) {
+		v.checksum = policy
+	}
+}
+
+// WithClock sets the source of the validation time, time.Now by default. A nil clock is ignored.
+func WithClock(clock func() time.Time) Option {
+	return func(v *Validator) {
+		if clock != nil {
+			v.clock = clock
+		}
+	}
+}
+
+func NewValidator(opts ...Option) *Validator {
+	v := &Validator{
+		lookup:    utils.DefaultLookup(),
+		normalize: utils.NormalizeCardNumber,
+		checksum:  ChecksumReport,
+		clock:     time.Now,
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+// NewCreditCard normalizes, validates and classifies the card number with the ranges effective now,
+// according to the validator's clock.
+func (v *Validator) NewCreditCard(cardNumber string) (CreditCard, error) {
+	return v.NewCreditCardAt(cardNumber, v.clock())
+}
+
+// NewCreditCardAt is NewCreditCard with the ranges effective at the given time, see utils.CardSchemaAt.
+func (v *Validator) NewCreditCardAt(cardNumber string, at time.Time) (CreditCard, error) {
+	number := v.normalize(cardNumber)
+	c, err := v.newCreditCard(number, at)
+	err = utils.LocateInRaw(err, cardNumber, number)
+
+	if v.audit != nil {
+		if auditErr := v.auditValidation(number, c, err); auditErr != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAudit, auditErr)
+		}
+	}
+	return c, err
+}
+
+// BINInfo returns the issuer data of the card when the lookup is a utils.BINLookup, otherwise nil.
+func (v *Validator) BINInfo(c CreditCard) (*pkg.BINInfo, error) {
+	if bins, ok := v.lookup.(utils.BINLookup); ok {
+		return bins.BINInfo(c.Number())
+	}
+	return nil, nil
+}
+
+func (v *Validator) newCreditCard(number string, at time.Time) (CreditCard, error) {
+	valid, schema, err := v.validateCardDetails(number, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &card{
+		number:      number,
+		valid:       valid,
+		schema:      schema,
+		validatedAt: v.clock(),
+	}, nil
+}
+
+func (v *Validator) validateCardDetails(cardNumber string, at time.Time) (bool, pkg.Schema, error) {
+	valid, err := utils.CardValid(cardNumber)
+	if err != nil {
+		return false, "", err
+	}
+	if !valid && v.checksum == ChecksumStrict {
+		return false, "", ErrChecksum
+	}
+	schema, err := utils.LookupCardSchemaAt(v.lookup, cardNumber, at)
+	if err != nil {
+		return false, "", err
+	}
+	return valid, schema, nil
+}
@@ -0,0 +1,158 @@
+//go:build unit
+
+package card
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubLookup struct {
+	schema  pkg.Schema
+	matched bool
+}
+
+func (sl *stubLookup) Match(string) (pkg.Schema, bool, error) {
+	return sl.schema, sl.matched, nil
+}
+
+func TestValidatorOptions(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	stripDashes := func(s string) string {
+		return strings.ReplaceAll(s, "-", "")
+	}
+
+	cases := []struct {
+		name           string
+		opts           []Option
+		cardNumber     string
+		expectedNumber string
+		expectedValid  bool
+		expectedSchema pkg.Schema
+		expectedError  error
+	}{
+		{
+			"should-use-default-behavior",
+			nil,
+			" 6759 6498 2643 8454 ",
+			"6759649826438454",
+			false,
+			pkg.SchemaMaestro,
+			nil,
+		},
+		{
+			"should-use-custom-lookup",
+			[]Option{WithLookup(&stubLookup{schema: "Test Network", matched: true})},
+			"4012888888881881",
+			"4012888888881881",
+			true,
+			"Test Network",
+			nil,
+		},
+		{
+			"should-use-unknown-for-unmatched-custom-lookup",
+			[]Option{WithLookup(&stubLookup{})},
+			"4012888888881881",
+			"4012888888881881",
+			true,
+			pkg.SchemaUnknown,
+			nil,
+		},
+		{
+			"should-use-custom-normalizer",
+			[]Option{WithNormalizer(stripDashes)},
+			"4012-8888-8888-1881",
+			"4012888888881881",
+			true,
+			pkg.SchemaVisa,
+			nil,
+		},
+		{
+			"should-accept-valid-number-in-strict-mode",
+			[]Option{WithChecksumPolicy(ChecksumStrict)},
+			"4012888888881881",
+			"4012888888881881",
+			true,
+			pkg.SchemaVisa,
+			nil,
+		},
+		{
+			"should-reject-invalid-number-in-strict-mode",
+			[]Option{WithChecksumPolicy(ChecksumStrict)},
+			"6759649826438454",
+			"",
+			false,
+			"",
+			ErrChecksum,
+		},
+		{
+			"should-ignore-nil-options",
+			[]Option{WithLookup(nil), WithNormalizer(nil), WithClock(nil)},
+			"4012 8888 8888 1881",
+			"4012888888881881",
+			true,
+			pkg.SchemaVisa,
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := append([]Option{WithClock(func() time.Time { return now })}, c.opts...)
+			creditCard, err := NewCreditCard(c.cardNumber, opts...)
+			if c.expectedError != nil {
+				assert.ErrorIs(t, err, c.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedNumber, creditCard.Number())
+			assert.Equal(t, c.expectedValid, creditCard.Valid())
+			assert.Equal(t, c.expectedSchema, creditCard.Schema())
+			assert.Equal(t, now, creditCard.(Validated).ValidatedAt())
+		})
+	}
+}
+
+func TestValidatorConcurrentUse(t *testing.T) {
+	validator := NewValidator(WithChecksumPolicy(ChecksumStrict))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				creditCard, err := validator.NewCreditCard("5105 1051 0510 5100")
+				assert.NoError(t, err)
+				assert.Equal(t, pkg.SchemaMasterCard, creditCard.Schema())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewCreditCardAt(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	historical, err := NewCreditCardAt("2221 0000 0000 0009", time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC), clock)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, historical.Schema())
+	// The validation itself happens now, only the classification is historical.
+	assert.Equal(t, now, historical.(Validated).ValidatedAt())
+
+	current, err := NewCreditCard("2221 0000 0000 0009", clock)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaMasterCard, current.Schema())
+
+	past := WithClock(func() time.Time { return time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC) })
+	fromClock, err := NewCreditCard("2221 0000 0000 0009", past)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, fromClock.Schema())
+}
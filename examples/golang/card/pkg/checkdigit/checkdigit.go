@@ -0,0 +1,46 @@
+package checkdigit
+
+import "errors"
+
+// All algorithms apply the same length rule: Compute needs a payload of at least one character,
+// Verify a code at least as long as its check characters, the payload may be empty there.
+// Empty input fails with ErrEmpty, a non-empty code shorter than its check characters with ErrTooShort.
+var (
+	ErrEmpty            = errors.New("checkdigit: empty input")
+	ErrTooShort         = errors.New("checkdigit: input shorter than its check digits")
+	ErrInvalidCharacter = errors.New("checkdigit: invalid character")
+)
+
+// Algorithm computes and verifies check characters appended to a payload.
+type Algorithm interface {
+	// Verify reports whether code, the payload followed by its check character(s), is intact.
+	Verify(code string) (bool, error)
+	// Compute returns the check character(s) to append to payload.
+	Compute(payload string) (string, error)
+}
+
+// checkLength applies the length rule to a code with the given number of check characters.
+func checkLength(code string, checkCharacters int) error {
+	switch {
+	case code == "":
+		return ErrEmpty
+	case len(code) < checkCharacters:
+		return ErrTooShort
+	}
+	return nil
+}
+
+// digits converts a decimal string, the numeric algorithms share it.
+func digits(s string) ([]int, error) {
+	if s == "" {
+		return nil, ErrEmpty
+	}
+	out := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return nil, ErrInvalidCharacter
+		}
+		out[i] = int(s[i] - '0')
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+//go:build unit
+
+package checkdigit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLengthRule(t *testing.T) {
+	cases := []struct {
+		name            string
+		algorithm       Algorithm
+		checkCharacters int
+	}{
+		{"should-apply-to-luhn", NewLuhn(), 1},
+		{"should-apply-to-verhoeff", Verhoeff{}, 1},
+		{"should-apply-to-damm", Damm{}, 1},
+		{"should-apply-to-mod97", Mod97{}, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.algorithm.Compute("")
+			assert.ErrorIs(t, err, ErrEmpty)
+			check, err := c.algorithm.Compute("7")
+			assert.NoError(t, err)
+			assert.Len(t, check, c.checkCharacters)
+
+			_, err = c.algorithm.Verify("")
+			assert.ErrorIs(t, err, ErrEmpty)
+			_, err = c.algorithm.Verify(strings.Repeat("0", c.checkCharacters))
+			assert.NoError(t, err)
+			if c.checkCharacters > 1 {
+				_, err = c.algorithm.Verify(strings.Repeat("0", c.checkCharacters-1))
+				assert.ErrorIs(t, err, ErrTooShort)
+			}
+		})
+	}
+}
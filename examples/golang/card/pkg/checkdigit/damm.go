@@ -0,0 +1,52 @@
+package checkdigit
+
+// Damm detects all single digit errors and all adjacent transpositions of decimal codes,
+// using a single quasigroup table.
+type Damm struct{}
+
+// dammTable is a totally anti-symmetric quasigroup of order 10 with a zero diagonal.
+var dammTable = [10][10]int{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+func (Damm) Verify(code string) (bool, error) {
+	if err := checkLength(code, 1); err != nil {
+		return false, err
+	}
+	interim, err := damm(code)
+	if err != nil {
+		return false, err
+	}
+	return interim == 0, nil
+}
+
+func (Damm) Compute(payload string) (string, error) {
+	interim, err := damm(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(rune('0' + interim)), nil
+}
+
+func damm(s string) (int, error) {
+	values, err := digits(s)
+	if err != nil {
+		return 0, err
+	}
+	interim := 0
+	for _, digit := range values {
+		interim = dammTable[interim][digit]
+	}
+	return interim, nil
+}
+
+var _ Algorithm = Damm{}
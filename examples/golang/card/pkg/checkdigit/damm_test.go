@@ -0,0 +1,56 @@
+//go:build unit
+
+package checkdigit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDamm(t *testing.T) {
+	cases := []struct {
+		name           string
+		payload        string
+		expectedResult string
+		expectedError  error
+	}{
+		{"should-compute-check-digit", "572", "4", nil},
+		{"should-compute-check-digit-for-long-payload", "4012888888881881", "", nil},
+		{"should-return-error-for-empty-payload", "", "", ErrEmpty},
+		{"should-return-error-for-non-digits", "57-2", "", ErrInvalidCharacter},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			check, err := Damm{}.Compute(c.payload)
+			assert.ErrorIs(t, err, c.expectedError)
+			if c.expectedResult != "" {
+				assert.Equal(t, c.expectedResult, check)
+			}
+			if err == nil {
+				valid, err := Damm{}.Verify(c.payload + check)
+				assert.NoError(t, err)
+				assert.True(t, valid)
+			}
+		})
+	}
+}
+
+func TestDammRejectsChangedCodes(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+	}{
+		{"should-reject-single-digit-error", "5734"},
+		{"should-reject-adjacent-transposition", "7524"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid, err := Damm{}.Verify(c.code)
+			assert.NoError(t, err)
+			assert.False(t, valid)
+		})
+	}
+}
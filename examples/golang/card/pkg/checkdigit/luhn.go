@@ -0,0 +1,93 @@
+package checkdigit
+
+import (
+	"errors"
+	"fmt"
+)
+
+const decimalAlphabet = "0123456789"
+
+// LuhnModN is the Luhn algorithm over an arbitrary alphabet of ASCII characters;
+// with the ten decimal digits it is the classic Luhn (mod 10) used for card numbers.
+type LuhnModN struct {
+	alphabet string
+	values   [256]int16 // Character to code point, -1 for characters outside the alphabet.
+}
+
+// NewLuhn returns the decimal Luhn algorithm.
+func NewLuhn() *LuhnModN {
+	// The decimal alphabet is valid, the error can be ignored.
+	l, _ := NewLuhnModN(decimalAlphabet)
+	return l
+}
+
+// NewLuhnModN returns the Luhn algorithm over the alphabet, ordered by code point.
+func NewLuhnModN(alphabet string) (*LuhnModN, error) {
+	if len(alphabet) < 2 || len(alphabet)%2 != 0 {
+		// With an odd base, doubling is not a permutation and single errors can go unnoticed.
+		return nil, errors.New("checkdigit: alphabet size must be even and at least 2")
+	}
+
+	l := &LuhnModN{alphabet: alphabet}
+	for i := range l.values {
+		l.values[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		ch := alphabet[i]
+		if ch >= 0x80 {
+			return nil, fmt.Errorf("checkdigit: non-ASCII alphabet character %q", ch)
+		}
+		if l.values[ch] >= 0 {
+			return nil, fmt.Errorf("checkdigit: duplicate alphabet character %q", ch)
+		}
+		l.values[ch] = int16(i)
+	}
+	return l, nil
+}
+
+func (l *LuhnModN) Verify(code string) (bool, error) {
+	if err := checkLength(code, 1); err != nil {
+		return false, err
+	}
+	sum, err := l.sum(code, false)
+	if err != nil {
+		return false, err
+	}
+	return sum%len(l.alphabet) == 0, nil
+}
+
+func (l *LuhnModN) Compute(payload string) (string, error) {
+	sum, err := l.sum(payload, true)
+	if err != nil {
+		return "", err
+	}
+	n := len(l.alphabet)
+	return string(l.alphabet[(n-sum%n)%n]), nil
+}
+
+// sum processes the characters from the right, doubling every second one; a doubled value
+// exceeding the base has its base-n digits summed (e.g., 18 becomes 1 + 8 = 9 for decimals).
+// The rightmost character is doubled when a check character is still to be appended.
+func (l *LuhnModN) sum(s string, doubleFirst bool) (int, error) {
+	if s == "" {
+		return 0, ErrEmpty
+	}
+
+	n := len(l.alphabet)
+	sum := 0
+	double := doubleFirst
+	for p := len(s) - 1; p >= 0; p-- {
+		value := int(l.values[s[p]])
+		if value < 0 {
+			return 0, ErrInvalidCharacter
+		}
+		if double {
+			value *= 2
+		}
+		sum += value/n + value%n
+		double = !double
+	}
+	return sum, nil
+}
+
+var _ Algorithm = &LuhnModN{}
@@ -0,0 +1,175 @@
+//go:build unit
+
+package checkdigit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLuhnVerify(t *testing.T) {
+	cases := []struct {
+		name           string
+		code           string
+		expectedResult bool
+		expectedError  error
+	}{
+		{
+			"should-pass-for-sum-0",
+			"000000",
+			true,
+			nil,
+		},
+		{
+			"should-pass-as-divisible-by-10",
+			"378282246310005",
+			true,
+			nil,
+		},
+		{
+			"should-not-pass-as-not-divisible-by-10",
+			"5237251624778132",
+			false,
+			nil,
+		},
+		{
+			"should-return-error-for-empty-code",
+			"",
+			false,
+			ErrEmpty,
+		},
+		{
+			"should-return-error-for-invalid-character-dot",
+			"378282246310005.",
+			false,
+			ErrInvalidCharacter,
+		},
+		{
+			"should-return-error-for-invalid-character-with-spaces",
+			"  3782 8224 6310 005 ",
+			false,
+			ErrInvalidCharacter,
+		},
+		{
+			"should-return-error-for-alphabetic-characters",
+			"378282246310005abc",
+			false,
+			ErrInvalidCharacter,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid, err := NewLuhn().Verify(c.code)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expectedResult, valid)
+		})
+	}
+}
+
+func TestLuhnModNCompute(t *testing.T) {
+	cases := []struct {
+		name           string
+		alphabet       string
+		payload        string
+		expectedResult string
+		expectedError  error
+	}{
+		{
+			"should-compute-decimal-check-digit",
+			"0123456789",
+			"7992739871",
+			"3",
+			nil,
+		},
+		{
+			"should-compute-zero-check-digit",
+			"0123456789",
+			"37828224631000",
+			"5",
+			nil,
+		},
+		{
+			"should-compute-hexadecimal-letters-check-character",
+			"abcdef",
+			"abcdef",
+			"e",
+			nil,
+		},
+		{
+			"should-compute-base-36-check-character",
+			"0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+			"GIFT2024XK7",
+			"U",
+			nil,
+		},
+		{
+			"should-return-error-for-character-outside-alphabet",
+			"abcdef",
+			"abcdefg",
+			"",
+			ErrInvalidCharacter,
+		},
+		{
+			"should-return-error-for-empty-payload",
+			"0123456789",
+			"",
+			"",
+			ErrEmpty,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l, err := NewLuhnModN(c.alphabet)
+			assert.NoError(t, err)
+
+			check, err := l.Compute(c.payload)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expectedResult, check)
+			if err == nil {
+				valid, err := l.Verify(c.payload + check)
+				assert.NoError(t, err)
+				assert.True(t, valid)
+			}
+		})
+	}
+}
+
+func TestNewLuhnModN(t *testing.T) {
+	cases := []struct {
+		name        string
+		alphabet    string
+		expectError bool
+	}{
+		{"should-accept-decimal-alphabet", "0123456789", false},
+		{"should-reject-odd-alphabet", "012", true},
+		{"should-reject-single-character-alphabet", "0", true},
+		{"should-reject-duplicate-characters", "0120", true},
+		{"should-reject-non-ascii-characters", "0ä", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewLuhnModN(c.alphabet)
+			assert.Equal(t, c.expectError, err != nil)
+		})
+	}
+}
+
+func TestLuhnDetectsSingleDigitErrors(t *testing.T) {
+	l := NewLuhn()
+	code := "4012888888881881"
+	for p := 0; p < len(code); p++ {
+		for d := byte('0'); d <= '9'; d++ {
+			if code[p] == d {
+				continue
+			}
+			changed := code[:p] + string(d) + code[p+1:]
+			valid, err := l.Verify(changed)
+			assert.NoError(t, err)
+			assert.False(t, valid, changed)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package checkdigit
+
+import "fmt"
+
+// Mod97 is ISO 7064 MOD 97-10 over decimal digits, with two check digits appended.
+// IBANs use it after moving the country code and check digits to the end and replacing letters by numbers.
+type Mod97 struct{}
+
+func (Mod97) Verify(code string) (bool, error) {
+	if err := checkLength(code, 2); err != nil {
+		return false, err
+	}
+	remainder, err := mod97(code)
+	if err != nil {
+		return false, err
+	}
+	return remainder == 1, nil
+}
+
+func (Mod97) Compute(payload string) (string, error) {
+	if payload == "" {
+		return "", ErrEmpty
+	}
+	remainder, err := mod97(payload + "00")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02d", 98-remainder), nil
+}
+
+// mod97 reduces digit by digit, so codes of any length fit into an int.
+func mod97(s string) (int, error) {
+	remainder := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, ErrInvalidCharacter
+		}
+		remainder = (remainder*10 + int(s[i]-'0')) % 97
+	}
+	return remainder, nil
+}
+
+var _ Algorithm = Mod97{}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package checkdigit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMod97Compute(t *testing.T) {
+	cases := []struct {
+		name           string
+		payload        string
+		expectedResult string
+		expectedError  error
+	}{
+		{"should-compute-check-digits", "794", "44", nil},
+		{"should-compute-check-digits-for-iban", "3214282912345698765432161182", "95", nil},
+		{"should-return-error-for-empty-payload", "", "", ErrEmpty},
+		{"should-return-error-for-letters", "DE89", "", ErrInvalidCharacter},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			check, err := Mod97{}.Compute(c.payload)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expectedResult, check)
+		})
+	}
+}
+
+func TestMod97Verify(t *testing.T) {
+	cases := []struct {
+		name           string
+		code           string
+		expectedResult bool
+		expectedError  error
+	}{
+		{"should-pass-for-remainder-1", "79444", true, nil},
+		{"should-pass-for-iban", "321428291234569876543216118295", true, nil},
+		{"should-not-pass-for-changed-digit", "79445", false, nil},
+		{"should-return-error-for-empty-code", "", false, ErrEmpty},
+		{"should-pass-for-check-digits-only", "01", true, nil},
+		{"should-return-error-for-too-short-code", "4", false, ErrTooShort},
+		{"should-return-error-for-letters", "79A44", false, ErrInvalidCharacter},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid, err := Mod97{}.Verify(c.code)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expectedResult, valid)
+		})
+	}
+}
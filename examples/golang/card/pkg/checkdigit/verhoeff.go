@@ -0,0 +1,67 @@
+package checkdigit
+
+// Verhoeff detects all single digit errors and all adjacent transpositions of decimal codes.
+type Verhoeff struct{}
+
+// verhoeffMultiplication is the multiplication table of the dihedral group D5.
+var verhoeffMultiplication = [10][10]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+	{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+	{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+	{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+	{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+	{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+	{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+	{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+	{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+}
+
+// verhoeffPermutation applies the position dependent permutation, it repeats every eight positions.
+var verhoeffPermutation = [8][10]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+	{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+	{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+	{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+	{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+	{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+	{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+}
+
+var verhoeffInverse = [10]int{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+
+func (Verhoeff) Verify(code string) (bool, error) {
+	if err := checkLength(code, 1); err != nil {
+		return false, err
+	}
+	c, err := verhoeff(code, 0)
+	if err != nil {
+		return false, err
+	}
+	return c == 0, nil
+}
+
+func (Verhoeff) Compute(payload string) (string, error) {
+	// The check digit will take position 0, so the payload starts at position 1.
+	c, err := verhoeff(payload, 1)
+	if err != nil {
+		return "", err
+	}
+	return string(rune('0' + verhoeffInverse[c])), nil
+}
+
+func verhoeff(s string, offset int) (int, error) {
+	values, err := digits(s)
+	if err != nil {
+		return 0, err
+	}
+	c := 0
+	for i := range values {
+		digit := values[len(values)-1-i]
+		c = verhoeffMultiplication[c][verhoeffPermutation[(i+offset)%8][digit]]
+	}
+	return c, nil
+}
+
+var _ Algorithm = Verhoeff{}
@@ -0,0 +1,50 @@
+//go:build unit
+
+package checkdigit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerhoeff(t *testing.T) {
+	cases := []struct {
+		name           string
+		payload        string
+		expectedResult string
+		expectedError  error
+	}{
+		{"should-compute-check-digit", "236", "3", nil},
+		{"should-compute-check-digit-for-long-payload", "12345", "1", nil},
+		{"should-compute-zero-check-digit", "142857", "0", nil},
+		{"should-return-error-for-empty-payload", "", "", ErrEmpty},
+		{"should-return-error-for-non-digits", "23a", "", ErrInvalidCharacter},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			check, err := Verhoeff{}.Compute(c.payload)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expectedResult, check)
+			if err == nil {
+				valid, err := Verhoeff{}.Verify(c.payload + check)
+				assert.NoError(t, err)
+				assert.True(t, valid)
+			}
+		})
+	}
+}
+
+func TestVerhoeffDetectsAdjacentTranspositions(t *testing.T) {
+	code := "2363"
+	for p := 0; p+1 < len(code); p++ {
+		if code[p] == code[p+1] {
+			continue
+		}
+		swapped := code[:p] + string(code[p+1]) + string(code[p]) + code[p+2:]
+		valid, err := Verhoeff{}.Verify(swapped)
+		assert.NoError(t, err)
+		assert.False(t, valid, swapped)
+	}
+}
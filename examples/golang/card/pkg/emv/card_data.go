@@ -0,0 +1,133 @@
+package emv
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"card/pkg/card"
+)
+
+const (
+	TagPAN            uint32 = 0x5A
+	TagTrack2         uint32 = 0x57
+	TagCardholderName uint32 = 0x5F20
+	TagExpiry         uint32 = 0x5F24
+	TagPANSequence    uint32 = 0x5F34
+)
+
+// track2Separator separates the PAN from the expiry and service code in Track 2 equivalent data.
+const track2Separator = 'D'
+
+var (
+	ErrNoPAN      = errors.New("invalid emv data: neither pan nor track 2 present")
+	ErrInvalidBCD = errors.New("invalid emv data: malformed bcd value")
+)
+
+// CardData holds the card related fields of an EMV chip payload.
+type CardData struct {
+	PAN            string // Digits of tag 5A, or the Track 2 PAN if 5A is absent.
+	Expiry         string // YYMMDD from tag 5F24.
+	CardholderName string // Tag 5F20 with the padding spaces trimmed.
+	Track2         string // Tag 57 as nibbles, the field separator is kept as 'D'.
+	PANSequence    int    // Tag 5F34, -1 when absent.
+}
+
+// ExtractCardDataHex decodes a hex encoded payload and extracts the card data from it.
+func ExtractCardDataHex(payload string) (CardData, error) {
+	objects, err := DecodeHex(payload)
+	if err != nil {
+		return CardData{}, err
+	}
+	return ExtractCardData(objects)
+}
+
+// ExtractCardData collects the card related tags from decoded objects,
+// regardless of the template (70, 77, ...) they are nested in.
+func ExtractCardData(objects []TLV) (CardData, error) {
+	data := CardData{PANSequence: -1}
+
+	if object, ok := Find(objects, TagTrack2); ok {
+		track2, err := unpackNibbles(object.Value, true)
+		if err != nil {
+			return CardData{}, err
+		}
+		data.Track2 = track2
+	}
+
+	if object, ok := Find(objects, TagPAN); ok {
+		pan, err := unpackBCD(object.Value)
+		if err != nil {
+			return CardData{}, err
+		}
+		data.PAN = pan
+	} else if data.Track2 != "" {
+		pan, _, _ := strings.Cut(data.Track2, string(track2Separator))
+		data.PAN = pan
+	}
+	if data.PAN == "" {
+		return CardData{}, ErrNoPAN
+	}
+
+	if object, ok := Find(objects, TagExpiry); ok {
+		expiry, err := unpackBCD(object.Value)
+		if err != nil {
+			return CardData{}, err
+		}
+		if len(expiry) != 6 {
+			return CardData{}, ErrInvalidBCD
+		}
+		data.Expiry = expiry
+	}
+
+	if object, ok := Find(objects, TagCardholderName); ok {
+		data.CardholderName = strings.TrimSpace(string(object.Value))
+	}
+
+	if object, ok := Find(objects, TagPANSequence); ok {
+		sequence, err := unpackBCD(object.Value)
+		if err != nil || sequence == "" || len(sequence) > 2 {
+			return CardData{}, ErrInvalidBCD
+		}
+		// One or two decimal digits, Atoi cannot fail here.
+		data.PANSequence, _ = strconv.Atoi(sequence)
+	}
+
+	return data, nil
+}
+
+// CreditCard builds a card from the extracted PAN through the regular validation path.
+func (d CardData) CreditCard() (card.CreditCard, error) {
+	return card.NewCreditCard(d.PAN)
+}
+
+// unpackBCD decodes compressed numeric (cn) data: packed digits right padded with 'F'.
+func unpackBCD(value []byte) (string, error) {
+	return unpackNibbles(value, false)
+}
+
+// unpackNibbles decodes packed nibbles. Padding 'F' is only accepted at the end,
+// the Track 2 separator 'D' only when allowed.
+func unpackNibbles(value []byte, allowSeparator bool) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(value) * 2)
+
+	padded := false
+	for _, b := range value {
+		for _, nibble := range [2]byte{b >> 4, b & 0x0F} {
+			switch {
+			case nibble == 0x0F:
+				padded = true
+			case padded:
+				return "", ErrInvalidBCD
+			case nibble <= 9:
+				sb.WriteByte('0' + nibble)
+			case nibble == 0x0D && allowSeparator:
+				sb.WriteByte(track2Separator)
+			default:
+				return "", ErrInvalidBCD
+			}
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,132 @@
+//go:build unit
+
+package emv
+
+import (
+	"testing"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCardData(t *testing.T) {
+	cases := []struct {
+		name         string
+		payload      string
+		expectedData CardData
+		expectError  error
+	}{
+		{
+			"should-extract-all-fields-from-template",
+			"703E5A0847617390010100105F24032212315F201243415244484F4C4445522F5649534120202057134761739001010010D22122011143804400000F5F340101",
+			CardData{
+				PAN:            "4761739001010010",
+				Expiry:         "221231",
+				CardholderName: "CARDHOLDER/VISA",
+				Track2:         "4761739001010010D22122011143804400000",
+				PANSequence:    1,
+			},
+			nil,
+		},
+		{
+			"should-fall-back-to-track2-pan",
+			"700F570D5413330089010012D2512201FF",
+			CardData{
+				PAN:         "5413330089010012",
+				Track2:      "5413330089010012D2512201",
+				PANSequence: -1,
+			},
+			nil,
+		},
+		{
+			"should-strip-bcd-padding",
+			"5A08476173900101001F",
+			CardData{
+				PAN:         "476173900101001",
+				PANSequence: -1,
+			},
+			nil,
+		},
+		{
+			"should-return-error-for-digit-after-padding",
+			"5A01F1",
+			CardData{},
+			ErrInvalidBCD,
+		},
+		{
+			"should-return-error-for-non-decimal-nibble",
+			"5A0241A1",
+			CardData{},
+			ErrInvalidBCD,
+		},
+		{
+			"should-return-error-for-short-expiry",
+			"5A0841111111111111115F24022212",
+			CardData{},
+			ErrInvalidBCD,
+		},
+		{
+			"should-return-error-for-long-pan-sequence",
+			"5A0841111111111111115F34020101",
+			CardData{},
+			ErrInvalidBCD,
+		},
+		{
+			"should-return-error-without-pan",
+			"5F340101",
+			CardData{},
+			ErrNoPAN,
+		},
+		{
+			"should-return-error-for-truncated-payload",
+			"703E5A084761739001",
+			CardData{},
+			ErrTruncated,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := ExtractCardDataHex(c.payload)
+			if c.expectError != nil {
+				assert.ErrorIs(t, err, c.expectError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.expectedData, data)
+			}
+		})
+	}
+}
+
+func TestCardDataCreditCard(t *testing.T) {
+	data, err := ExtractCardDataHex("5A0847617390010100105F340101")
+	assert.NoError(t, err)
+
+	creditCard, err := data.CreditCard()
+	assert.NoError(t, err)
+	assert.Equal(t, "4761739001010010", creditCard.Number())
+	assert.True(t, creditCard.Valid())
+	assert.Equal(t, pkg.SchemaVisa, creditCard.Schema())
+}
+
+func FuzzExtractCardData(f *testing.F) {
+	f.Add([]byte{0x5A, 0x08, 0x47, 0x61, 0x73, 0x90, 0x01, 0x01, 0x00, 0x10})
+	f.Add([]byte{0x70, 0x04, 0x57, 0x02, 0x41, 0xDF})
+	f.Add([]byte{0x5F, 0x34, 0x01, 0x99})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		objects, err := Decode(data)
+		if err != nil {
+			return
+		}
+		cardData, err := ExtractCardData(objects)
+		if err != nil {
+			return
+		}
+		if cardData.PAN == "" {
+			t.Fatal("extracted card data without pan")
+		}
+		_, _ = cardData.CreditCard()
+	})
+}
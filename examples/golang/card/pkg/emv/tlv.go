@@ -0,0 +1,176 @@
+package emv
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxTagLength caps multi-byte tags; EMV never uses more than three bytes,
+	// anything longer is treated as garbage rather than read until the input ends.
+	maxTagLength = 4
+	// maxLengthBytes caps the long form of the length field (0x81-0x84).
+	maxLengthBytes = 4
+	// maxDepth guards the recursion over constructed objects against hostile nesting.
+	maxDepth = 16
+)
+
+var (
+	ErrTruncated        = errors.New("invalid tlv: truncated input")
+	ErrTagTooLong       = errors.New("invalid tlv: tag too long")
+	ErrLengthTooLong    = errors.New("invalid tlv: length field too long")
+	ErrIndefiniteLength = errors.New("invalid tlv: indefinite length is not supported")
+	ErrTooDeep          = errors.New("invalid tlv: constructed objects nested too deep")
+)
+
+// TLV is a single BER-TLV data object.
+// Children is populated for constructed objects only, Value always holds the raw value bytes.
+type TLV struct {
+	Tag      uint32
+	Value    []byte
+	Children []TLV
+}
+
+// Constructed reports whether the tag marks a constructed object (bit 6 of the first tag byte).
+func (t TLV) Constructed() bool {
+	return tagConstructed(t.Tag)
+}
+
+func (t TLV) String() string {
+	return fmt.Sprintf("%X: %X", t.Tag, t.Value)
+}
+
+// DecodeHex decodes a hex encoded BER-TLV payload, spaces are ignored.
+func DecodeHex(payload string) ([]TLV, error) {
+	data, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(payload), " ", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tlv: %w", err)
+	}
+	return Decode(data)
+}
+
+// Decode parses a sequence of BER-TLV data objects, descending into constructed ones.
+// The returned values reference the input slice, so it must not be modified afterwards.
+func Decode(data []byte) ([]TLV, error) {
+	return decode(data, 0)
+}
+
+func decode(data []byte, depth int) ([]TLV, error) {
+	if depth > maxDepth {
+		return nil, ErrTooDeep
+	}
+
+	var objects []TLV
+	for len(data) > 0 {
+		// EMV allows '00' padding before, between and after data objects.
+		if data[0] == 0x00 {
+			data = data[1:]
+			continue
+		}
+
+		tag, n, err := readTag(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+
+		length, n, err := readLength(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+
+		if length > len(data) {
+			return nil, ErrTruncated
+		}
+		object := TLV{Tag: tag, Value: data[:length]}
+		data = data[length:]
+
+		if object.Constructed() {
+			if object.Children, err = decode(object.Value, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// readTag returns the tag and the number of bytes it occupies.
+func readTag(data []byte) (uint32, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrTruncated
+	}
+
+	tag := uint32(data[0])
+	if data[0]&0x1F != 0x1F {
+		return tag, 1, nil
+	}
+
+	// Subsequent bytes follow while bit 8 is set.
+	for i := 1; ; i++ {
+		if i >= maxTagLength {
+			return 0, 0, ErrTagTooLong
+		}
+		if i >= len(data) {
+			return 0, 0, ErrTruncated
+		}
+		tag = tag<<8 | uint32(data[i])
+		if data[i]&0x80 == 0 {
+			return tag, i + 1, nil
+		}
+	}
+}
+
+// readLength returns the value length and the number of bytes the length field occupies.
+func readLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrTruncated
+	}
+
+	first := data[0]
+	if first < 0x80 {
+		return int(first), 1, nil
+	}
+
+	count := int(first & 0x7F)
+	if count == 0 {
+		return 0, 0, ErrIndefiniteLength
+	} else if count > maxLengthBytes {
+		return 0, 0, ErrLengthTooLong
+	} else if count >= len(data) {
+		return 0, 0, ErrTruncated
+	}
+
+	length := 0
+	for _, b := range data[1 : count+1] {
+		length = length<<8 | int(b)
+	}
+	if length < 0 {
+		return 0, 0, ErrLengthTooLong
+	}
+	return length, count + 1, nil
+}
+
+func tagConstructed(tag uint32) bool {
+	first := tag
+	for first > 0xFF {
+		first >>= 8
+	}
+	return first&0x20 != 0
+}
+
+// Find returns the first object with the given tag, searching constructed objects depth-first.
+func Find(objects []TLV, tag uint32) (TLV, bool) {
+	for _, object := range objects {
+		if object.Tag == tag {
+			return object, true
+		}
+		if found, ok := Find(object.Children, tag); ok {
+			return found, true
+		}
+	}
+	return TLV{}, false
+}
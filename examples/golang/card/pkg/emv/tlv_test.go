@@ -0,0 +1,174 @@
+//go:build unit
+
+package emv
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name          string
+		payload       string
+		expectedTags  []uint32
+		expectedValue []byte
+		expectError   error
+	}{
+		{
+			"should-decode-primitive",
+			"5A0841111111111111 11",
+			[]uint32{TagPAN},
+			[]byte{0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11},
+			nil,
+		},
+		{
+			"should-decode-multi-byte-tag",
+			"5F34 01 01",
+			[]uint32{TagPANSequence},
+			[]byte{0x01},
+			nil,
+		},
+		{
+			"should-decode-long-form-length",
+			"5F20 81 02 4142",
+			[]uint32{TagCardholderName},
+			[]byte("AB"),
+			nil,
+		},
+		{
+			"should-skip-zero-padding",
+			"00 5F34 01 01 0000",
+			[]uint32{TagPANSequence},
+			[]byte{0x01},
+			nil,
+		},
+		{
+			"should-return-error-for-truncated-value",
+			"5A 08 4111",
+			nil,
+			nil,
+			ErrTruncated,
+		},
+		{
+			"should-return-error-for-missing-length",
+			"5F34",
+			nil,
+			nil,
+			ErrTruncated,
+		},
+		{
+			"should-return-error-for-truncated-tag",
+			"5F",
+			nil,
+			nil,
+			ErrTruncated,
+		},
+		{
+			"should-return-error-for-truncated-long-form-length",
+			"5A 82 01",
+			nil,
+			nil,
+			ErrTruncated,
+		},
+		{
+			"should-return-error-for-indefinite-length",
+			"70 80 5F34 01 01 0000",
+			nil,
+			nil,
+			ErrIndefiniteLength,
+		},
+		{
+			"should-return-error-for-too-long-length-field",
+			"5A 85 0000000001 41",
+			nil,
+			nil,
+			ErrLengthTooLong,
+		},
+		{
+			"should-return-error-for-too-long-tag",
+			"5F 81 81 81 01 01",
+			nil,
+			nil,
+			ErrTagTooLong,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objects, err := DecodeHex(c.payload)
+			if c.expectError != nil {
+				assert.ErrorIs(t, err, c.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			tags := make([]uint32, 0, len(objects))
+			for _, object := range objects {
+				tags = append(tags, object.Tag)
+			}
+			assert.Equal(t, c.expectedTags, tags)
+			assert.Equal(t, c.expectedValue, objects[0].Value)
+		})
+	}
+}
+
+func TestDecodeConstructed(t *testing.T) {
+	objects, err := DecodeHex("70 0A 5F34 01 01 E1 04 5F34 01 02")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.True(t, objects[0].Constructed())
+	assert.Len(t, objects[0].Children, 2)
+	assert.Equal(t, uint32(0xE1), objects[0].Children[1].Tag)
+
+	nested, ok := Find(objects, TagPANSequence)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x01}, nested.Value)
+}
+
+func TestDecodeTooDeep(t *testing.T) {
+	payload := []byte{0x5F, 0x34, 0x01, 0x01}
+	for i := 0; i <= maxDepth+1; i++ {
+		payload = append([]byte{0x70, byte(len(payload))}, payload...)
+	}
+	_, err := Decode(payload)
+	assert.ErrorIs(t, err, ErrTooDeep)
+}
+
+func TestDecodeHexInvalid(t *testing.T) {
+	_, err := DecodeHex("5A0G")
+	assert.Error(t, err)
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range []string{
+		"5A0841111111111111 11",
+		"70 0A 5F34 01 01 E1 04 5F34 01 02",
+		"5F20 81 02 4142",
+		"70 80 0000",
+		"5A 84 FFFFFFFF",
+	} {
+		data, _ := hex.DecodeString(strings.ReplaceAll(seed, " ", ""))
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		objects, err := Decode(data)
+		if err != nil {
+			return
+		}
+		assertWithin(t, objects, len(data))
+	})
+}
+
+// assertWithin checks that no decoded value claims more bytes than the input holds.
+func assertWithin(t *testing.T, objects []TLV, size int) {
+	for _, object := range objects {
+		if len(object.Value) > size {
+			t.Fatalf("value of tag %X is longer than the input", object.Tag)
+		}
+		assertWithin(t, object.Children, len(object.Value))
+	}
+}
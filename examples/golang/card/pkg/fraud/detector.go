@@ -0,0 +1,290 @@
+package fraud
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"card/pkg/card"
+)
+
+// binLength is the number of leading digits grouped as one BIN.
+const binLength = 6
+
+type Action int
+
+const (
+	ActionAllow Action = iota
+	ActionAlert
+	ActionBlock
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionAlert:
+		return "alert"
+	case ActionBlock:
+		return "block"
+	default:
+		return "allow"
+	}
+}
+
+// Event describes a single validation request. It carries no clear PAN:
+// the first six and last four digits are what PCI DSS allows to be kept.
+type Event struct {
+	BIN         string
+	Last4       string
+	Fingerprint string
+	ClientID    string
+	Time        time.Time
+
+	// Sequence is the account number, the digits between the BIN and the check digit, shifted by
+	// an offset keyed per BIN. Distances between the accounts of a BIN are kept, the account is not.
+	Sequence      uint64
+	SequenceWidth int // Number of account digits, 0 when the sequence is unknown.
+}
+
+// NewEvent derives an event from a validated card, the fingerprint and sequence are keyed with key.
+func NewEvent(c card.CreditCard, key []byte, clientID string, t time.Time) Event {
+	number := c.Number()
+	e := Event{
+		BIN:         number[:min(binLength, len(number))],
+		Last4:       number[max(0, len(number)-4):],
+		Fingerprint: card.Fingerprint(key, c),
+		ClientID:    clientID,
+		Time:        t,
+	}
+	e.Sequence, e.SequenceWidth = sequence(key, number)
+	return e
+}
+
+// maxSequenceWidth keeps the modulus of sequences within uint64.
+const maxSequenceWidth = 18
+
+// sequence returns the keyed account sequence of a card number and its width, enumerated PANs differ
+// there by small steps.
+func sequence(key []byte, number string) (uint64, int) {
+	width := len(number) - binLength - 1
+	if width <= 0 || width > maxSequenceWidth {
+		return 0, 0
+	}
+	account, err := strconv.ParseUint(number[binLength:len(number)-1], 10, 64)
+	if err != nil {
+		return 0, 0
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("sequence:" + number[:binLength]))
+	offset := binary.BigEndian.Uint64(mac.Sum(nil))
+
+	modulus := sequenceModulus(width)
+	return (account%modulus + offset%modulus) % modulus, width
+}
+
+// sequenceModulus returns 10^width.
+func sequenceModulus(width int) uint64 {
+	modulus := uint64(1)
+	for range width {
+		modulus *= 10
+	}
+	return modulus
+}
+
+// Config holds the thresholds of the detector. Counts are of distinct cards within Window,
+// a zero threshold disables the check.
+type Config struct {
+	Window time.Duration
+
+	ClientAlert int
+	ClientBlock int
+	BINAlert    int
+	BINBlock    int
+
+	// SequenceGap is the largest distance between account sequences still considered neighbours,
+	// SequenceBlock the number of neighbouring cards in one BIN that blocks.
+	SequenceGap   int
+	SequenceBlock int
+}
+
+// DefaultConfig returns thresholds suited for a checkout with low per-client card variety.
+func DefaultConfig() Config {
+	return Config{
+		Window:        10 * time.Minute,
+		ClientAlert:   3,
+		ClientBlock:   6,
+		BINAlert:      20,
+		BINBlock:      50,
+		SequenceGap:   10,
+		SequenceBlock: 5,
+	}
+}
+
+// Decision is the detector's verdict on an event, Reasons explain alerts and blocks.
+type Decision struct {
+	Action  Action
+	Reasons []string
+}
+
+func (d *Decision) raise(action Action, reason string) {
+	if action > d.Action {
+		d.Action = action
+	}
+	d.Reasons = append(d.Reasons, reason)
+}
+
+// Detector spots card-testing attacks: many cards validated by few clients,
+// or many cards with neighbouring account numbers from the same BIN.
+type Detector struct {
+	cfg     Config
+	store   Store
+	clock   func() time.Time
+	onAlert func(Event, Decision)
+}
+
+type Option func(*Detector)
+
+func WithStore(store Store) Option {
+	return func(d *Detector) {
+		d.store = store
+	}
+}
+
+func WithClock(clock func() time.Time) Option {
+	return func(d *Detector) {
+		d.clock = clock
+	}
+}
+
+// WithAlertFunc registers a callback for every event not allowed.
+func WithAlertFunc(onAlert func(Event, Decision)) Option {
+	return func(d *Detector) {
+		d.onAlert = onAlert
+	}
+}
+
+func NewDetector(cfg Config, opts ...Option) *Detector {
+	d := &Detector{
+		cfg:   cfg,
+		store: NewMemoryStore(),
+		clock: time.Now,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Observe records the event and decides on it. Events without a time are stamped with the clock.
+func (d *Detector) Observe(e Event) (Decision, error) {
+	now := d.clock()
+	if e.Time.IsZero() {
+		e.Time = now
+	}
+	since := now.Add(-d.cfg.Window)
+
+	clientEvents, err := d.store.Append("client:"+e.ClientID, e, since)
+	if err != nil {
+		return Decision{}, err
+	}
+	binEvents, err := d.store.Append("bin:"+e.BIN, e, since)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	var decision Decision
+	d.checkVelocity(&decision, "client "+e.ClientID, distinct(clientEvents), d.cfg.ClientAlert, d.cfg.ClientBlock)
+	d.checkVelocity(&decision, "bin "+e.BIN, distinct(binEvents), d.cfg.BINAlert, d.cfg.BINBlock)
+	if d.cfg.SequenceBlock > 0 {
+		if neighbours := longestSequence(binEvents, d.cfg.SequenceGap); neighbours >= d.cfg.SequenceBlock {
+			decision.raise(ActionBlock, fmt.Sprintf("bin %s: %d cards with sequential account numbers", e.BIN, neighbours))
+		}
+	}
+
+	if decision.Action != ActionAllow && d.onAlert != nil {
+		d.onAlert(e, decision)
+	}
+	return decision, nil
+}
+
+func (d *Detector) checkVelocity(decision *Decision, subject string, cards, alert, block int) {
+	switch {
+	case block > 0 && cards >= block:
+		decision.raise(ActionBlock, fmt.Sprintf("%s: %d cards within %s", subject, cards, d.cfg.Window))
+	case alert > 0 && cards >= alert:
+		decision.raise(ActionAlert, fmt.Sprintf("%s: %d cards within %s", subject, cards, d.cfg.Window))
+	}
+}
+
+// distinct counts cards, so retries of the same card do not add up.
+func distinct(events []Event) int {
+	seen := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		seen[e.Fingerprint] = struct{}{}
+	}
+	return len(seen)
+}
+
+// longestSequence returns the size of the largest group of distinct cards
+// whose account sequences are chained by steps of at most gap. Only sequences of the same width compare.
+func longestSequence(events []Event, gap int) int {
+	byWidth := map[int]map[uint64]struct{}{}
+	seen := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		if _, ok := seen[e.Fingerprint]; ok || e.SequenceWidth == 0 {
+			continue
+		}
+		seen[e.Fingerprint] = struct{}{}
+		if byWidth[e.SequenceWidth] == nil {
+			byWidth[e.SequenceWidth] = map[uint64]struct{}{}
+		}
+		byWidth[e.SequenceWidth][e.Sequence] = struct{}{}
+	}
+
+	longest := 0
+	for width, set := range byWidth {
+		sequences := make([]uint64, 0, len(set))
+		for seq := range set {
+			sequences = append(sequences, seq)
+		}
+		sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+		longest = max(longest, longestChain(sequences, sequenceModulus(width), uint64(gap)))
+	}
+	return longest
+}
+
+// longestChain scans sorted sequences on a ring of size modulus, as the keyed offset may wrap an
+// enumeration around. The scan starts after the largest step, which no chain spans unless all do.
+func longestChain(sequences []uint64, modulus, gap uint64) int {
+	n := len(sequences)
+	step := func(i int) uint64 {
+		// The step from sequences[i-1] to sequences[i], wrapping for i == 0.
+		if i == 0 {
+			return modulus - sequences[n-1] + sequences[0]
+		}
+		return sequences[i] - sequences[i-1]
+	}
+
+	start := 0
+	for i := 1; i < n; i++ {
+		if step(i) > step(start) {
+			start = i
+		}
+	}
+
+	longest, run := 0, 0
+	for k := 0; k < n; k++ {
+		i := (start + k) % n
+		if k > 0 && step(i) <= gap {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	return longest
+}
@@ -0,0 +1,190 @@
+//go:build unit
+
+package fraud
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"card/pkg/card"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	return fc.now
+}
+
+func newTestEvent(bin string, account int, clientID string) Event {
+	return Event{
+		BIN:           bin,
+		Last4:         fmt.Sprintf("%03d0", account%1000),
+		Fingerprint:   fmt.Sprintf("%s-%d", bin, account),
+		ClientID:      clientID,
+		Sequence:      uint64(account),
+		SequenceWidth: 9,
+	}
+}
+
+// newCardEvent derives an event from a 16 digit card number of the BIN and account, like NewEvent in production.
+func newCardEvent(t *testing.T, bin string, account uint64, clientID string) Event {
+	creditCard, err := card.NewCreditCard(fmt.Sprintf("%s%09d0", bin, account))
+	assert.NoError(t, err)
+	return NewEvent(creditCard, []byte("secret"), clientID, time.Time{})
+}
+
+func TestDetectorClientVelocity(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)}
+	cfg := Config{Window: time.Minute, ClientAlert: 2, ClientBlock: 3}
+	detector := NewDetector(cfg, WithClock(clock.Now))
+
+	cases := []struct {
+		name           string
+		event          Event
+		advance        time.Duration
+		expectedAction Action
+	}{
+		{"should-allow-first-card", newTestEvent("411111", 100, "client-a"), 0, ActionAllow},
+		{"should-not-count-retries", newTestEvent("411111", 100, "client-a"), time.Second, ActionAllow},
+		{"should-not-count-other-clients", newTestEvent("411111", 500, "client-b"), time.Second, ActionAllow},
+		{"should-alert-second-card", newTestEvent("522222", 300, "client-a"), time.Second, ActionAlert},
+		{"should-block-third-card", newTestEvent("533333", 700, "client-a"), time.Second, ActionBlock},
+		{"should-allow-after-window", newTestEvent("544444", 900, "client-a"), 2 * time.Minute, ActionAllow},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clock.now = clock.now.Add(c.advance)
+			decision, err := detector.Observe(c.event)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedAction, decision.Action, decision.Reasons)
+		})
+	}
+}
+
+func TestDetectorBINVelocity(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)}
+	cfg := Config{Window: time.Minute, BINAlert: 2, BINBlock: 3}
+	detector := NewDetector(cfg, WithClock(clock.Now))
+
+	expected := []Action{ActionAllow, ActionAlert, ActionBlock}
+	for i, action := range expected {
+		decision, err := detector.Observe(newTestEvent("411111", i*100, fmt.Sprintf("client-%d", i)))
+		assert.NoError(t, err)
+		assert.Equal(t, action, decision.Action)
+	}
+}
+
+func TestDetectorSequentialEnumeration(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)}
+	cfg := Config{Window: time.Minute, SequenceGap: 2, SequenceBlock: 3}
+
+	var alerts []Decision
+	detector := NewDetector(cfg, WithClock(clock.Now), WithAlertFunc(func(_ Event, d Decision) {
+		alerts = append(alerts, d)
+	}))
+
+	cases := []struct {
+		name           string
+		account        int
+		expectedAction Action
+	}{
+		{"should-allow-first-account", 100, ActionAllow},
+		{"should-allow-distant-account", 400, ActionAllow},
+		{"should-allow-two-neighbours", 102, ActionAllow},
+		{"should-block-third-neighbour", 101, ActionBlock},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			decision, err := detector.Observe(newTestEvent("411111", c.account, fmt.Sprintf("client-%d", c.account)))
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedAction, decision.Action)
+		})
+	}
+
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, []string{"bin 411111: 3 cards with sequential account numbers"}, alerts[0].Reasons)
+}
+
+func TestDetectorStampsEvents(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	detector := NewDetector(DefaultConfig(), WithStore(store), WithClock(func() time.Time { return now }))
+
+	_, err := detector.Observe(newTestEvent("411111", 100, "client-a"))
+	assert.NoError(t, err)
+	assert.Equal(t, now, store.events["client:client-a"][0].Time)
+}
+
+func TestNewEvent(t *testing.T) {
+	creditCard, err := card.NewCreditCard("4012888888881881")
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	e := NewEvent(creditCard, []byte("secret"), "client-a", now)
+	assert.Equal(t, "401288", e.BIN)
+	assert.Equal(t, "1881", e.Last4)
+	assert.Equal(t, card.Fingerprint([]byte("secret"), creditCard), e.Fingerprint)
+	assert.Equal(t, "client-a", e.ClientID)
+	assert.Equal(t, now, e.Time)
+
+	assert.Equal(t, 9, e.SequenceWidth)
+	// The sequence hides the account but keeps the distance to neighbouring accounts.
+	assert.NotEqual(t, uint64(888888188), e.Sequence)
+	neighbour, err := card.NewCreditCard("4012888888881907")
+	assert.NoError(t, err)
+	assert.Equal(t, (e.Sequence+2)%1_000_000_000, NewEvent(neighbour, []byte("secret"), "client-a", now).Sequence)
+}
+
+func TestDetectorEnumerationAcrossDigitBoundaries(t *testing.T) {
+	cfg := Config{Window: time.Minute, SequenceGap: 2, SequenceBlock: 5}
+	detector := NewDetector(cfg)
+
+	// Enumeration carrying into the higher account digits.
+	var decision Decision
+	for account := uint64(199998); account <= 200002; account++ {
+		var err error
+		decision, err = detector.Observe(newCardEvent(t, "411111", account, fmt.Sprintf("client-%d", account)))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, ActionBlock, decision.Action)
+}
+
+func TestDetectorBusyBINWithRandomCards(t *testing.T) {
+	cfg := Config{Window: time.Hour, SequenceGap: 10, SequenceBlock: 5}
+	detector := NewDetector(cfg)
+
+	random := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 2000; i++ {
+		e := newCardEvent(t, "411111", random.Uint64N(1_000_000_000), fmt.Sprintf("client-%d", i))
+		decision, err := detector.Observe(e)
+		assert.NoError(t, err)
+		assert.Equal(t, ActionAllow, decision.Action, decision.Reasons)
+	}
+}
+
+func TestLongestChain(t *testing.T) {
+	cases := []struct {
+		name      string
+		sequences []uint64
+		expected  int
+	}{
+		{"should-count-single-sequence", []uint64{5}, 1},
+		{"should-chain-neighbours", []uint64{1, 2, 3, 50, 51}, 3},
+		{"should-chain-across-wrap", []uint64{0, 1, 97, 98, 99}, 5},
+		{"should-not-chain-distant", []uint64{0, 20, 40, 60, 80}, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, longestChain(c.sequences, 100, 2))
+		})
+	}
+}
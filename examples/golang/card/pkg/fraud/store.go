@@ -0,0 +1,61 @@
+package fraud
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps the events of a sliding window per key.
+// Implementations must be safe for concurrent use; a shared backend lets several instances
+// of a service see the same attack.
+type Store interface {
+	// Append records the event under key, drops the events of that key older than since
+	// and returns the remaining ones, including the appended event.
+	Append(key string, e Event, since time.Time) ([]Event, error)
+}
+
+// MemoryStore is a Store local to the process.
+type MemoryStore struct {
+	mu     sync.Mutex
+	events map[string][]Event
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{events: map[string][]Event{}}
+}
+
+func (s *MemoryStore) Append(key string, e Event, since time.Time) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := append(keepSince(s.events[key], since), e)
+	s.events[key] = events
+
+	// Callers get their own copy, the stored slice keeps changing.
+	return append([]Event(nil), events...), nil
+}
+
+// Prune drops events older than since for all keys, including keys no longer appended to.
+// Call it periodically to bound memory.
+func (s *MemoryStore) Prune(since time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, events := range s.events {
+		if events = keepSince(events, since); len(events) == 0 {
+			delete(s.events, key)
+		} else {
+			s.events[key] = events
+		}
+	}
+}
+
+func keepSince(events []Event, since time.Time) []Event {
+	kept := events[:0]
+	for _, e := range events {
+		if !e.Time.Before(since) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,37 @@
+//go:build unit
+
+package fraud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	start := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+
+	events, err := store.Append("key", Event{Fingerprint: "a", Time: start}, start)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	events, err = store.Append("key", Event{Fingerprint: "b", Time: start.Add(time.Minute)}, start)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	events, err = store.Append("key", Event{Fingerprint: "c", Time: start.Add(2 * time.Minute)}, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []Event{
+		{Fingerprint: "b", Time: start.Add(time.Minute)},
+		{Fingerprint: "c", Time: start.Add(2 * time.Minute)},
+	}, events)
+
+	_, err = store.Append("other", Event{Fingerprint: "d", Time: start}, start)
+	assert.NoError(t, err)
+
+	store.Prune(start.Add(2 * time.Minute))
+	assert.Len(t, store.events, 1)
+	assert.Len(t, store.events["key"], 1)
+}
@@ -0,0 +1,229 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when neither the requested locale nor its language has a message.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var embedded embed.FS
+
+// forms holds the text of a message per plural category ("one", "few", "many", "other").
+// Messages without plural forms have "other" only.
+type forms map[string]string
+
+// Catalog holds the messages of all locales. It is read-only and safe for concurrent use.
+type Catalog struct {
+	fallback string
+	messages map[string]map[string]forms // Locale to key to forms.
+}
+
+var defaultCatalog = sync.OnceValue(func() *Catalog {
+	locales, err := fs.Sub(embedded, "locales")
+	if err != nil {
+		panic(err)
+	}
+	catalog, err := NewCatalog(locales, DefaultLocale)
+	if err != nil {
+		// The catalog is embedded, the unit tests make sure it loads.
+		panic(err)
+	}
+	return catalog
+})
+
+// DefaultCatalog returns the embedded catalog with en, de, fr, es and ru.
+func DefaultCatalog() *Catalog {
+	return defaultCatalog()
+}
+
+// NewCatalog loads a catalog from the <locale>.json files at the root of fsys, e.g. de.json or pt-br.json.
+// A file maps message keys to a text or to its plural forms:
+//
+//	{"card.empty": "Please enter your card number.",
+//	 "card.too_long": {"one": "{count} digit too long.", "other": "{count} digits too long."}}
+//
+// Messages missing in a locale fall back to its language and then to the fallback locale,
+// which must be present.
+func NewCatalog(fsys fs.FS, fallback string) (*Catalog, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{fallback: normalizeLocale(fallback), messages: map[string]map[string]forms{}}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		messages, err := parseMessages(data)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: %s: %w", file, err)
+		}
+		c.messages[normalizeLocale(strings.TrimSuffix(path.Base(file), ".json"))] = messages
+	}
+
+	if _, ok := c.messages[c.fallback]; !ok {
+		return nil, fmt.Errorf("i18n: no messages for fallback locale %q", fallback)
+	}
+	return c, nil
+}
+
+func parseMessages(data []byte) (map[string]forms, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]forms, len(raw))
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			messages[key] = forms{"other": text}
+			continue
+		}
+
+		var plural forms
+		if err := json.Unmarshal(value, &plural); err != nil {
+			return nil, fmt.Errorf("message %q is neither a text nor plural forms", key)
+		}
+		if _, ok := plural["other"]; !ok {
+			return nil, fmt.Errorf("message %q has no \"other\" plural form", key)
+		}
+		for category := range plural {
+			if !validCategories[category] {
+				return nil, fmt.Errorf("message %q has unknown plural category %q", key, category)
+			}
+		}
+		messages[key] = plural
+	}
+	return messages, nil
+}
+
+// Locales returns the locales of the catalog, sorted.
+func (c *Catalog) Locales() []string {
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Format renders the message in the locale, or the closest one with the message.
+// Placeholders like {position} are replaced by the parameters, the plural form is chosen by "count".
+// It returns the key itself when no locale has the message.
+func (c *Catalog) Format(locale string, msg Message) string {
+	for _, candidate := range c.fallbacks(locale) {
+		f, ok := c.messages[candidate][msg.Key]
+		if !ok {
+			continue
+		}
+
+		text := f["other"]
+		if count, ok := msg.Params["count"].(int); ok {
+			if form, ok := f[pluralCategory(candidate, count)]; ok {
+				text = form
+			}
+		}
+		for name, value := range msg.Params {
+			text = strings.ReplaceAll(text, "{"+name+"}", fmt.Sprint(value))
+		}
+		return text
+	}
+	return msg.Key
+}
+
+// fallbacks returns the locale, its language and the fallback locale, e.g. de-at, de and en.
+func (c *Catalog) fallbacks(locale string) []string {
+	locale = normalizeLocale(locale)
+	candidates := make([]string, 0, 3)
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if language := baseLanguage(locale); language != locale {
+			candidates = append(candidates, language)
+		}
+	}
+	return append(candidates, c.fallback)
+}
+
+// Match picks the best supported locale for an Accept-Language header, e.g. "de-CH, fr;q=0.8, *;q=0.1".
+// A language matches its regional variants in both directions, e.g. "de" matches "de-at" when the catalog has
+// no "de", the first variant in sort order wins; without a match the fallback locale is returned.
+func (c *Catalog) Match(acceptLanguage string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			return c.fallback
+		}
+		if _, ok := c.messages[tag]; ok {
+			return tag
+		}
+		if _, ok := c.messages[baseLanguage(tag)]; ok {
+			return baseLanguage(tag)
+		}
+		for _, locale := range c.Locales() {
+			if baseLanguage(locale) == baseLanguage(tag) {
+				return locale
+			}
+		}
+	}
+	return c.fallback
+}
+
+type weightedTag struct {
+	tag    string
+	weight float64
+}
+
+// parseAcceptLanguage returns the tags by descending quality, dropping those with q=0.
+func parseAcceptLanguage(header string) []string {
+	var weighted []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalizeLocale(tag)
+		if tag == "" {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if name == "q" {
+				if _, err := fmt.Sscanf(value, "%g", &weight); err != nil {
+					weight = 0
+				}
+			}
+		}
+		if weight > 0 {
+			weighted = append(weighted, weightedTag{tag, weight})
+		}
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].weight > weighted[j].weight
+	})
+	tags := make([]string, len(weighted))
+	for i, w := range weighted {
+		tags[i] = w.tag
+	}
+	return tags
+}
+
+// normalizeLocale lower-cases the tag and uses hyphens, de_AT becomes de-at.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func baseLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
@@ -0,0 +1,170 @@
+//go:build unit
+
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultCatalog(t *testing.T) {
+	catalog := DefaultCatalog()
+	assert.Equal(t, []string{"de", "en", "es", "fr", "ru"}, catalog.Locales())
+
+	// Every locale translates every message, falling back is for partial custom catalogs.
+	for _, locale := range catalog.Locales() {
+		for key, f := range catalog.messages[DefaultLocale] {
+			translated, ok := catalog.messages[locale][key]
+			assert.True(t, ok, "%s: %s missing", locale, key)
+			if len(f) > 1 {
+				assert.Greater(t, len(translated), 1, "%s: %s has no plural forms", locale, key)
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	catalog := DefaultCatalog()
+
+	cases := []struct {
+		name     string
+		locale   string
+		msg      Message
+		expected string
+	}{
+		{
+			"should-format-parameter",
+			"en",
+			Message{Key: KeyNonDigit, Params: map[string]any{"position": 5}},
+			"The card number may only contain digits, please check the character at position 5.",
+		},
+		{
+			"should-format-german-plural",
+			"de",
+			Message{Key: KeyTooLong, Params: map[string]any{"count": 19}},
+			"Die Kartennummer darf höchstens 19 Ziffern haben.",
+		},
+		{
+			"should-format-russian-one",
+			"ru",
+			Message{Key: KeyTooLong, Params: map[string]any{"count": 21}},
+			"Номер карты может содержать не более 21 цифры.",
+		},
+		{
+			"should-format-russian-few",
+			"ru",
+			Message{Key: KeyTooLong, Params: map[string]any{"count": 3}},
+			"Номер карты может содержать не более 3 цифр.",
+		},
+		{
+			"should-format-french-one-for-zero",
+			"fr",
+			Message{Key: KeyTooLong, Params: map[string]any{"count": 0}},
+			"Le numéro de carte peut comporter au plus 0 chiffre.",
+		},
+		{
+			"should-fall-back-to-language",
+			"es_MX",
+			Message{Key: KeyEmpty},
+			"Introduzca el número de su tarjeta.",
+		},
+		{
+			"should-fall-back-to-default-locale",
+			"pt-BR",
+			Message{Key: KeyEmpty},
+			"Please enter your card number.",
+		},
+		{
+			"should-return-key-of-unknown-message",
+			"de",
+			Message{Key: "card.unknown"},
+			"card.unknown",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, catalog.Format(c.locale, c.msg))
+		})
+	}
+}
+
+func TestNewCatalog(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.json":    {Data: []byte(`{"greeting": "Hello", "farewell": "Goodbye"}`)},
+		"de.json":    {Data: []byte(`{"greeting": "Hallo"}`)},
+		"de_AT.json": {Data: []byte(`{"greeting": "Servus"}`)},
+	}
+	catalog, err := NewCatalog(fsys, "en")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"de", "de-at", "en"}, catalog.Locales())
+
+	assert.Equal(t, "Servus", catalog.Format("de-AT", Message{Key: "greeting"}))
+	assert.Equal(t, "Hallo", catalog.Format("de-CH", Message{Key: "greeting"}))
+	// Missing in de-at and de.
+	assert.Equal(t, "Goodbye", catalog.Format("de-AT", Message{Key: "farewell"}))
+}
+
+func TestMatchRegionalVariant(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.json":    {Data: []byte(`{"greeting": "Hello"}`)},
+		"de-at.json": {Data: []byte(`{"greeting": "Servus"}`)},
+		"de-ch.json": {Data: []byte(`{"greeting": "Grüezi"}`)},
+	}
+	catalog, err := NewCatalog(fsys, "en")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "de-at", catalog.Match("de"))
+	assert.Equal(t, "de-ch", catalog.Match("de-CH"))
+	assert.Equal(t, "de-at", catalog.Match("de-DE"))
+	assert.Equal(t, "en", catalog.Match("fr"))
+}
+
+func TestNewCatalogErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"should-return-error-for-malformed-json", `{"greeting": `},
+		{"should-return-error-for-wrong-type", `{"greeting": 1}`},
+		{"should-return-error-for-missing-other-form", `{"greeting": {"one": "Hello"}}`},
+		{"should-return-error-for-unknown-category", `{"greeting": {"single": "Hello", "other": "Hello"}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewCatalog(fstest.MapFS{"en.json": {Data: []byte(c.data)}}, "en")
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewCatalog(fstest.MapFS{"de.json": {Data: []byte(`{}`)}}, "en")
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	catalog := DefaultCatalog()
+
+	cases := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{"should-match-exact-language", "fr", "fr"},
+		{"should-match-language-of-region", "de-CH", "de"},
+		{"should-prefer-higher-quality", "es;q=0.5, ru;q=0.9", "ru"},
+		{"should-skip-unsupported-languages", "pt-BR, ja;q=0.9, fr;q=0.8", "fr"},
+		{"should-skip-excluded-languages", "de;q=0, es", "es"},
+		{"should-use-default-for-wildcard", "pt, *;q=0.5", "en"},
+		{"should-use-default-for-empty-header", "", "en"},
+		{"should-use-default-for-malformed-quality", "de;q=high", "en"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, catalog.Match(c.acceptLanguage))
+		})
+	}
+}
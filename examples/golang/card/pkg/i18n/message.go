@@ -0,0 +1,40 @@
+package i18n
+
+import (
+	"errors"
+
+	"card/pkg/card"
+	"card/pkg/utils"
+)
+
+// Message keys of card validation errors.
+const (
+	KeyEmpty    = "card.empty"
+	KeyTooLong  = "card.too_long"  // Parameters: count, the longest allowed length.
+	KeyNonDigit = "card.non_digit" // Parameters: position of the first character which is not a digit.
+	KeyChecksum = "card.checksum"
+	KeyInvalid  = "card.invalid"
+)
+
+// Message is a message key with its parameters, rendered by Catalog.Format.
+type Message struct {
+	Key    string
+	Params map[string]any
+}
+
+// FromError maps a card validation error to its message.
+func FromError(err error) Message {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.Is(err, utils.ErrEmpty):
+		return Message{Key: KeyEmpty}
+	case errors.Is(err, utils.ErrTooLong):
+		return Message{Key: KeyTooLong, Params: map[string]any{"count": utils.MaxCardLength}}
+	case errors.As(err, &validationErr) && validationErr.Kind == utils.ErrNonDigit:
+		return Message{Key: KeyNonDigit, Params: map[string]any{"position": validationErr.Position}}
+	case errors.Is(err, card.ErrChecksum):
+		return Message{Key: KeyChecksum}
+	default:
+		return Message{Key: KeyInvalid}
+	}
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package i18n
+
+import (
+	"errors"
+	"testing"
+
+	"card/pkg/card"
+	"card/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromError(t *testing.T) {
+	cases := []struct {
+		name       string
+		cardNumber string
+		opts       []card.Option
+		expected   Message
+	}{
+		{
+			"should-map-empty",
+			"",
+			nil,
+			Message{Key: KeyEmpty},
+		},
+		{
+			"should-map-too-long",
+			"11111111111111111111",
+			nil,
+			Message{Key: KeyTooLong, Params: map[string]any{"count": 19}},
+		},
+		{
+			"should-map-non-digit-with-position",
+			"4012-8888",
+			nil,
+			Message{Key: KeyNonDigit, Params: map[string]any{"position": 5}},
+		},
+		{
+			"should-map-checksum",
+			"4012888888881882",
+			[]card.Option{card.WithChecksumPolicy(card.ChecksumStrict)},
+			Message{Key: KeyChecksum},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := card.NewCreditCard(c.cardNumber, c.opts...)
+			assert.Equal(t, c.expected, FromError(err))
+		})
+	}
+}
+
+func TestFromErrorOfAnalyze(t *testing.T) {
+	_, err := utils.Analyze("4012-8888")
+	assert.Equal(t, Message{Key: KeyNonDigit, Params: map[string]any{"position": 5}}, FromError(err))
+	assert.Equal(t, Message{Key: KeyInvalid}, FromError(errors.New("boom")))
+}
@@ -0,0 +1,32 @@
+package i18n
+
+var validCategories = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+// pluralCategory selects the CLDR plural category of a count for the language of the locale.
+// Only integer rules of the supported languages are implemented, others use "other".
+func pluralCategory(locale string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch baseLanguage(locale) {
+	case "en", "de", "es":
+		if n == 1 {
+			return "one"
+		}
+	case "fr":
+		if n == 0 || n == 1 {
+			return "one"
+		}
+	case "ru":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	}
+	return "other"
+}
@@ -0,0 +1,35 @@
+//go:build unit
+
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluralCategory(t *testing.T) {
+	cases := []struct {
+		name     string
+		locale   string
+		counts   []int
+		expected string
+	}{
+		{"should-be-english-one", "en", []int{1, -1}, "one"},
+		{"should-be-english-other", "en-GB", []int{0, 2, 11, 21}, "other"},
+		{"should-be-french-one", "fr", []int{0, 1}, "one"},
+		{"should-be-french-other", "fr", []int{2, 19}, "other"},
+		{"should-be-russian-one", "ru", []int{1, 21, 101}, "one"},
+		{"should-be-russian-few", "ru", []int{2, 4, 22, 104}, "few"},
+		{"should-be-russian-many", "ru", []int{0, 5, 11, 12, 14, 19, 111}, "many"},
+		{"should-be-other-for-unknown-language", "ja", []int{1, 2}, "other"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for _, count := range c.counts {
+				assert.Equal(t, c.expected, pluralCategory(c.locale, count), "count %d", count)
+			}
+		})
+	}
+}
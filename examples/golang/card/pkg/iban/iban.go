@@ -0,0 +1,148 @@
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"card/pkg/checkdigit"
+)
+
+// maxLength is the longest IBAN ISO 13616 allows.
+const maxLength = 34
+
+var (
+	ErrEmpty            = errors.New("invalid IBAN: empty")
+	ErrTooLong          = errors.New("invalid IBAN: too long")
+	ErrInvalidCharacter = errors.New("invalid IBAN: contains invalid characters")
+	ErrUnknownCountry   = errors.New("invalid IBAN: unknown country")
+	ErrLength           = errors.New("invalid IBAN: wrong length for country")
+	ErrStructure        = errors.New("invalid IBAN: account number does not match country format")
+	ErrChecksum         = errors.New("invalid IBAN: checksum mismatch")
+)
+
+// IBAN is a validated International Bank Account Number in electronic format.
+type IBAN struct {
+	code string
+}
+
+// NormalizeIBAN removes spaces and trims the input string like utils.NormalizeCardNumber,
+// and upper-cases it, as IBANs are often written in lower case.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(iban, " ", "")))
+}
+
+// NewIBAN normalizes and validates an IBAN: the country's length and BBAN structure from the
+// registry and the ISO 7064 MOD 97-10 check digits.
+// Input example: DE89 3704 0044 0532 0130 00
+func NewIBAN(iban string) (IBAN, error) {
+	code := NormalizeIBAN(iban)
+	if err := validate(code); err != nil {
+		return IBAN{}, err
+	}
+	return IBAN{code: code}, nil
+}
+
+func validate(code string) error {
+	if len(code) == 0 {
+		return ErrEmpty
+	} else if len(code) > maxLength {
+		return ErrTooLong
+	}
+	for i := 0; i < len(code); i++ {
+		if !classAlphanumeric.contains(code[i]) {
+			return fmt.Errorf("%w: %q at position %d", ErrInvalidCharacter, code[i], i+1)
+		}
+	}
+
+	if len(code) < 2 {
+		return ErrUnknownCountry
+	}
+	c, ok := registry()[code[:2]]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCountry, code[:2])
+	}
+	if len(code) != c.length {
+		return fmt.Errorf("%w: %s IBANs have %d characters, got %d", ErrLength, code[:2], c.length, len(code))
+	}
+	if !classDigit.contains(code[2]) || !classDigit.contains(code[3]) {
+		return fmt.Errorf("%w: check digits must be numeric", ErrStructure)
+	}
+
+	position := 4
+	for _, s := range c.bban {
+		for _, ch := range []byte(code[position : position+s.length]) {
+			if !s.class.contains(ch) {
+				return fmt.Errorf("%w: %q at position %d", ErrStructure, ch, position+1)
+			}
+			position++
+		}
+	}
+
+	valid, err := checkdigit.Mod97{}.Verify(numeric(code))
+	if err != nil {
+		return err
+	} else if !valid {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// numeric moves the country code and check digits to the end and replaces letters
+// by two digit numbers (A = 10, ..., Z = 35), as MOD 97-10 expects for IBANs.
+func numeric(code string) string {
+	var sb strings.Builder
+	sb.Grow(2 * len(code))
+	for _, ch := range []byte(code[4:] + code[:4]) {
+		if ch >= 'A' && ch <= 'Z' {
+			fmt.Fprintf(&sb, "%d", ch-'A'+10)
+		} else {
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+// Electronic returns the IBAN without spaces, as stored and transmitted.
+func (i IBAN) Electronic() string {
+	return i.code
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 country code.
+func (i IBAN) CountryCode() string {
+	return i.code[:2]
+}
+
+func (i IBAN) CheckDigits() string {
+	return i.code[2:4]
+}
+
+// BBAN returns the country specific Basic Bank Account Number.
+func (i IBAN) BBAN() string {
+	return i.code[4:]
+}
+
+// String returns the print format, groups of four characters separated by spaces.
+func (i IBAN) String() string {
+	return group(i.code)
+}
+
+// Masked returns the print format with all but the country code, check digits and
+// last four characters replaced by '*'.
+func (i IBAN) Masked() string {
+	if len(i.code) <= 8 {
+		return group(i.code)
+	}
+	return group(i.code[:4] + strings.Repeat("*", len(i.code)-8) + i.code[len(i.code)-4:])
+}
+
+func group(code string) string {
+	var sb strings.Builder
+	for p := 0; p < len(code); p += 4 {
+		if p > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(code[p:min(p+4, len(code))])
+	}
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+//go:build unit
+
+package iban
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIBAN(t *testing.T) {
+	cases := []struct {
+		name          string
+		iban          string
+		expected      string
+		expectedError error
+	}{
+		{
+			"should-be-valid-german-iban",
+			"DE89370400440532013000",
+			"DE89370400440532013000",
+			nil,
+		},
+		{
+			"should-normalize-print-format",
+			"  gb82 west 1234 5698 7654 32 ",
+			"GB82WEST12345698765432",
+			nil,
+		},
+		{
+			"should-be-valid-with-alphanumeric-bban",
+			"FR1420041010050500013M02606",
+			"FR1420041010050500013M02606",
+			nil,
+		},
+		{
+			"should-be-valid-shortest-sepa-iban",
+			"NO9386011117947",
+			"NO9386011117947",
+			nil,
+		},
+		{
+			"should-return-error-empty",
+			"   ",
+			"",
+			ErrEmpty,
+		},
+		{
+			"should-return-error-too-long",
+			"DE89370400440532013000000000000000000",
+			"",
+			ErrTooLong,
+		},
+		{
+			"should-return-error-for-punctuation",
+			"DE89-3704-0044-0532-0130-00",
+			"",
+			ErrInvalidCharacter,
+		},
+		{
+			"should-return-error-for-unknown-country",
+			"XX89370400440532013000",
+			"",
+			ErrUnknownCountry,
+		},
+		{
+			"should-return-error-for-wrong-length",
+			"DE8937040044053201300",
+			"",
+			ErrLength,
+		},
+		{
+			"should-return-error-for-letter-in-numeric-bban",
+			"DE89370400440532O13000",
+			"",
+			ErrStructure,
+		},
+		{
+			"should-return-error-for-digit-in-bank-code",
+			"GB82W3ST12345698765432",
+			"",
+			ErrStructure,
+		},
+		{
+			"should-return-error-for-checksum-mismatch",
+			"DE89370400440532013001",
+			"",
+			ErrChecksum,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			iban, err := NewIBAN(c.iban)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expected, iban.Electronic())
+		})
+	}
+}
+
+func TestIBANFormat(t *testing.T) {
+	cases := []struct {
+		name           string
+		iban           string
+		expectedPrint  string
+		expectedMasked string
+	}{
+		{
+			"should-format-german-iban",
+			"DE89370400440532013000",
+			"DE89 3704 0044 0532 0130 00",
+			"DE89 **** **** **** **30 00",
+		},
+		{
+			"should-format-iban-with-full-last-group",
+			"BE68539007547034",
+			"BE68 5390 0754 7034",
+			"BE68 **** **** 7034",
+		},
+		{
+			"should-format-maltese-iban",
+			"MT84MALT011000012345MTLCAST001S",
+			"MT84 MALT 0110 0001 2345 MTLC AST0 01S",
+			"MT84 **** **** **** **** **** ***0 01S",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			iban, err := NewIBAN(c.iban)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedPrint, iban.String())
+			assert.Equal(t, c.expectedMasked, iban.Masked())
+		})
+	}
+}
+
+func TestIBANParts(t *testing.T) {
+	iban, err := NewIBAN("GB82 WEST 1234 5698 7654 32")
+	assert.NoError(t, err)
+	assert.Equal(t, "GB", iban.CountryCode())
+	assert.Equal(t, "82", iban.CheckDigits())
+	assert.Equal(t, "WEST12345698765432", iban.BBAN())
+}
@@ -0,0 +1,111 @@
+package iban
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// registry.json holds the SEPA countries of the SWIFT IBAN registry: the IBAN length
+// and the BBAN structure in registry notation, e.g. "8!n10!n" for 8 digits followed by 10 digits.
+//
+//go:embed registry.json
+var registryData []byte
+
+// charClass is a character class of the registry notation.
+type charClass byte
+
+const (
+	classDigit        charClass = 'n' // 0-9
+	classUpper        charClass = 'a' // A-Z
+	classAlphanumeric charClass = 'c' // 0-9, A-Z
+)
+
+type segment struct {
+	length int
+	class  charClass
+}
+
+type country struct {
+	length int
+	bban   []segment
+}
+
+type registryEntry struct {
+	Length int    `json:"length"`
+	BBAN   string `json:"bban"`
+}
+
+var registry = sync.OnceValue(func() map[string]country {
+	countries, err := parseRegistry(registryData)
+	if err != nil {
+		// The registry is embedded, the unit tests make sure it parses.
+		panic(err)
+	}
+	return countries
+})
+
+func parseRegistry(data []byte) (map[string]country, error) {
+	var entries map[string]registryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid IBAN registry: %w", err)
+	}
+
+	countries := make(map[string]country, len(entries))
+	for code, entry := range entries {
+		bban, err := parseStructure(entry.BBAN)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IBAN registry: %s: %w", code, err)
+		}
+		bbanLength := 0
+		for _, s := range bban {
+			bbanLength += s.length
+		}
+		// Country code and check digits precede the BBAN.
+		if 4+bbanLength != entry.Length {
+			return nil, fmt.Errorf("invalid IBAN registry: %s: length %d does not match structure %q", code, entry.Length, entry.BBAN)
+		}
+		countries[code] = country{length: entry.Length, bban: bban}
+	}
+	return countries, nil
+}
+
+// parseStructure parses the fixed length segments of the registry notation, such as "4!a6!n8!n".
+func parseStructure(structure string) ([]segment, error) {
+	var segments []segment
+	for rest := structure; rest != ""; {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i+2 > len(rest) || rest[i] != '!' {
+			return nil, fmt.Errorf("malformed structure %q", structure)
+		}
+		length, _ := strconv.Atoi(rest[:i])
+		class := charClass(rest[i+1])
+		if class != classDigit && class != classUpper && class != classAlphanumeric {
+			return nil, fmt.Errorf("unknown character class %q in structure %q", class, structure)
+		}
+		segments = append(segments, segment{length: length, class: class})
+		rest = rest[i+2:]
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty structure")
+	}
+	return segments, nil
+}
+
+func (c charClass) contains(ch byte) bool {
+	isDigit := ch >= '0' && ch <= '9'
+	isUpper := ch >= 'A' && ch <= 'Z'
+	switch c {
+	case classDigit:
+		return isDigit
+	case classUpper:
+		return isUpper
+	default:
+		return isDigit || isUpper
+	}
+}
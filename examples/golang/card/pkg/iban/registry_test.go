@@ -0,0 +1,62 @@
+//go:build unit
+
+package iban
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedRegistry(t *testing.T) {
+	countries, err := parseRegistry(registryData)
+	assert.NoError(t, err)
+	assert.Contains(t, countries, "DE")
+	assert.Equal(t, 22, countries["DE"].length)
+}
+
+func TestParseRegistry(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        string
+		expectError bool
+	}{
+		{
+			"should-parse-valid-entry",
+			`{"DE": {"length": 22, "bban": "8!n10!n"}}`,
+			false,
+		},
+		{
+			"should-return-error-for-length-mismatch",
+			`{"DE": {"length": 21, "bban": "8!n10!n"}}`,
+			true,
+		},
+		{
+			"should-return-error-for-unknown-character-class",
+			`{"DE": {"length": 22, "bban": "8!x10!n"}}`,
+			true,
+		},
+		{
+			"should-return-error-for-missing-fixed-length-marker",
+			`{"DE": {"length": 22, "bban": "8n10!n"}}`,
+			true,
+		},
+		{
+			"should-return-error-for-empty-structure",
+			`{"DE": {"length": 4, "bban": ""}}`,
+			true,
+		},
+		{
+			"should-return-error-for-malformed-json",
+			`{"DE": `,
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseRegistry([]byte(c.data))
+			assert.Equal(t, c.expectError, err != nil)
+		})
+	}
+}
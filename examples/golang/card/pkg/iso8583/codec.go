@@ -0,0 +1,94 @@
+package iso8583
+
+// track2SeparatorNibble carries '=' in packed Track 2 data.
+const track2SeparatorNibble = 0x0D
+
+type reader struct {
+	data []byte
+}
+
+func (r *reader) next(n int) ([]byte, error) {
+	if n > len(r.data) {
+		return nil, ErrTruncated
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out, nil
+}
+
+// digits reads count characters. Packed numeric values are right aligned (leading '0' pad),
+// packed Track 2 values are left aligned (trailing 'F' pad).
+func (r *reader) digits(count int, encoding Encoding, fieldType FieldType) (string, error) {
+	if encoding == EncodingASCII {
+		raw, err := r.next(count)
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	}
+
+	raw, err := r.next((count + 1) / 2)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 0, len(raw)*2)
+	for _, b := range raw {
+		for _, nibble := range [2]byte{b >> 4, b & 0x0F} {
+			switch {
+			case nibble <= 9:
+				out = append(out, '0'+nibble)
+			case nibble == track2SeparatorNibble && fieldType == Track2:
+				out = append(out, '=')
+			case nibble == 0x0F && fieldType == Track2:
+				out = append(out, 'F')
+			default:
+				return "", ErrInvalidContent
+			}
+		}
+	}
+
+	if count%2 == 0 {
+		return string(out), nil
+	}
+	if fieldType == Track2 {
+		if out[len(out)-1] != 'F' {
+			return "", ErrInvalidContent
+		}
+		return string(out[:len(out)-1]), nil
+	}
+	if out[0] != '0' {
+		return "", ErrInvalidContent
+	}
+	return string(out[1:]), nil
+}
+
+// packDigits appends value, the caller has to validate the characters beforehand.
+func packDigits(out []byte, value string, encoding Encoding, fieldType FieldType) []byte {
+	if encoding == EncodingASCII {
+		return append(out, value...)
+	}
+
+	if len(value)%2 != 0 {
+		if fieldType == Track2 {
+			value += "F"
+		} else {
+			value = "0" + value
+		}
+	}
+	for i := 0; i < len(value); i += 2 {
+		out = append(out, nibble(value[i])<<4|nibble(value[i+1]))
+	}
+	return out
+}
+
+func nibble(ch byte) byte {
+	switch ch {
+	case '=':
+		return track2SeparatorNibble
+	case 'F':
+		return 0x0F
+	default:
+		return ch - '0'
+	}
+}
@@ -0,0 +1,215 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"card/pkg/card"
+)
+
+// Message is an ISO 8583 message reduced to its MTI and the data elements in use.
+// Values are kept as decoded characters; Track 2 uses '=' as separator regardless of encoding.
+type Message struct {
+	MTI    string
+	Fields map[int]string
+}
+
+// NewMessage creates an empty message with the given message type indicator.
+func NewMessage(mti string) *Message {
+	return &Message{MTI: mti, Fields: map[int]string{}}
+}
+
+// PAN returns the primary account number from DE2.
+func (m *Message) PAN() (string, bool) {
+	pan, ok := m.Fields[FieldPAN]
+	return pan, ok
+}
+
+// Expiry returns the expiration date (YYMM) from DE14.
+func (m *Message) Expiry() (string, bool) {
+	expiry, ok := m.Fields[FieldExpiry]
+	return expiry, ok
+}
+
+// CreditCard validates and classifies the PAN carried in DE2.
+func (m *Message) CreditCard() (card.CreditCard, error) {
+	pan, ok := m.PAN()
+	if !ok {
+		return nil, fmt.Errorf("field %d: %w", FieldPAN, ErrMissingField)
+	}
+	return card.NewCreditCard(pan)
+}
+
+// Unpack parses a message according to the specification.
+func (s *Spec) Unpack(data []byte) (*Message, error) {
+	r := &reader{data: data}
+
+	mti, err := r.digits(4, s.MTI, Numeric)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMTI(mti); err != nil {
+		return nil, err
+	}
+
+	fields, err := s.readBitmap(r)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := NewMessage(mti)
+	for _, field := range fields {
+		fs, ok := s.Fields[field]
+		if !ok {
+			return nil, fmt.Errorf("field %d: %w", field, ErrUnsupportedField)
+		}
+		value, err := fs.unpack(r)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", field, err)
+		}
+		if err := fs.validate(field, value); err != nil {
+			return nil, err
+		}
+		msg.Fields[field] = value
+	}
+
+	if len(r.data) > 0 {
+		return nil, ErrTrailingData
+	}
+	return msg, nil
+}
+
+// Pack builds the wire representation of a message according to the specification.
+func (s *Spec) Pack(msg *Message) ([]byte, error) {
+	if err := validateMTI(msg.MTI); err != nil {
+		return nil, err
+	}
+
+	fields := make([]int, 0, len(msg.Fields))
+	for field := range msg.Fields {
+		if field <= FieldSecondaryBitmap || field > maxField {
+			return nil, fmt.Errorf("field %d: %w", field, ErrUnsupportedField)
+		}
+		if _, ok := s.Fields[field]; !ok {
+			return nil, fmt.Errorf("field %d: %w", field, ErrUnsupportedField)
+		}
+		fields = append(fields, field)
+	}
+	sort.Ints(fields)
+
+	out := packDigits(nil, msg.MTI, s.MTI, Numeric)
+	out = s.writeBitmap(out, fields)
+	for _, field := range fields {
+		fs := s.Fields[field]
+		value := msg.Fields[field]
+		if err := fs.validate(field, value); err != nil {
+			return nil, err
+		}
+		out = fs.pack(out, value)
+	}
+	return out, nil
+}
+
+func validateMTI(mti string) error {
+	if len(mti) != 4 {
+		return ErrInvalidMTI
+	}
+	for i := 0; i < len(mti); i++ {
+		if mti[i] < '0' || mti[i] > '9' {
+			return ErrInvalidMTI
+		}
+	}
+	return nil
+}
+
+// readBitmap returns the present data elements in ascending order, without the secondary bitmap bit.
+func (s *Spec) readBitmap(r *reader) ([]int, error) {
+	bitmap, err := s.readBitmapBlock(r)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap[0]&0x80 != 0 {
+		secondary, err := s.readBitmapBlock(r)
+		if err != nil {
+			return nil, err
+		}
+		bitmap = append(bitmap, secondary...)
+	}
+
+	var fields []int
+	for i, b := range bitmap {
+		for bit := 0; bit < 8; bit++ {
+			field := i*8 + bit + 1
+			if b&(0x80>>bit) != 0 && field != FieldSecondaryBitmap {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+func (s *Spec) readBitmapBlock(r *reader) ([]byte, error) {
+	if s.Bitmap == EncodingBCD {
+		block, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), block...), nil
+	}
+
+	block, err := r.next(16)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := hex.DecodeString(string(block))
+	if err != nil {
+		return nil, fmt.Errorf("invalid iso8583 message: malformed bitmap: %w", err)
+	}
+	return decoded, nil
+}
+
+func (s *Spec) writeBitmap(out []byte, fields []int) []byte {
+	size := 8
+	if len(fields) > 0 && fields[len(fields)-1] > 64 {
+		size = 16
+	}
+
+	bitmap := make([]byte, size)
+	if size == 16 {
+		bitmap[0] |= 0x80
+	}
+	for _, field := range fields {
+		bitmap[(field-1)/8] |= 0x80 >> ((field - 1) % 8)
+	}
+
+	if s.Bitmap == EncodingBCD {
+		return append(out, bitmap...)
+	}
+	return append(out, strings.ToUpper(hex.EncodeToString(bitmap))...)
+}
+
+func (fs FieldSpec) unpack(r *reader) (string, error) {
+	length := fs.Length
+	if digits := fs.LengthType.digits(); digits > 0 {
+		prefix, err := r.digits(digits, fs.Encoding, Numeric)
+		if err != nil {
+			return "", err
+		}
+		if length, err = parseLength(prefix); err != nil {
+			return "", err
+		}
+		if length > fs.Length {
+			return "", ErrInvalidLength
+		}
+	}
+	return r.digits(length, fs.Encoding, fs.Type)
+}
+
+func (fs FieldSpec) pack(out []byte, value string) []byte {
+	if digits := fs.LengthType.digits(); digits > 0 {
+		out = packDigits(out, formatLength(len(value), digits), fs.Encoding, Numeric)
+	}
+	return packDigits(out, value, fs.Encoding, fs.Type)
+}
@@ -0,0 +1,148 @@
+//go:build unit
+
+package iso8583
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMessage() *Message {
+	msg := NewMessage("0100")
+	msg.Fields[FieldPAN] = "4012888888881881"
+	msg.Fields[FieldExpiry] = "2512"
+	msg.Fields[FieldPOSEntryMode] = "051"
+	msg.Fields[FieldTrack2] = "4012888888881881=25121011000012345"
+	return msg
+}
+
+func TestPackASCII(t *testing.T) {
+	data, err := NewSpec(EncodingASCII).Pack(newTestMessage())
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"0100"+"4004040020000000"+"164012888888881881"+"2512"+"051"+"344012888888881881=25121011000012345",
+		string(data),
+	)
+}
+
+func TestPackBCD(t *testing.T) {
+	data, err := NewSpec(EncodingBCD).Pack(newTestMessage())
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"0100"+"4004040020000000"+"164012888888881881"+"2512"+"0051"+"344012888888881881D25121011000012345",
+		strings.ToUpper(hex.EncodeToString(data)),
+	)
+}
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name     string
+		encoding Encoding
+	}{
+		{"should-round-trip-ascii", EncodingASCII},
+		{"should-round-trip-bcd", EncodingBCD},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := NewSpec(c.encoding)
+			spec.Fields[70] = FieldSpec{Type: Numeric, LengthType: Fixed, Length: 3, Encoding: c.encoding}
+
+			msg := newTestMessage()
+			msg.Fields[70] = "301"
+
+			data, err := spec.Pack(msg)
+			assert.NoError(t, err)
+
+			unpacked, err := spec.Unpack(data)
+			assert.NoError(t, err)
+			assert.Equal(t, msg, unpacked)
+		})
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        string
+		expectError error
+	}{
+		{
+			"should-return-error-for-truncated-mti",
+			"01",
+			ErrTruncated,
+		},
+		{
+			"should-return-error-for-malformed-mti",
+			"01X0" + "4000000000000000" + "164012888888881881",
+			ErrInvalidMTI,
+		},
+		{
+			"should-return-error-for-truncated-field",
+			"0100" + "4000000000000000" + "1640128888",
+			ErrTruncated,
+		},
+		{
+			"should-return-error-for-unsupported-field",
+			"0100" + "6000000000000000" + "164012888888881881" + "123456",
+			ErrUnsupportedField,
+		},
+		{
+			"should-return-error-for-too-long-pan",
+			"0100" + "4000000000000000" + "20" + "40128888888818810000",
+			ErrInvalidLength,
+		},
+		{
+			"should-return-error-for-non-digit-pan",
+			"0100" + "4000000000000000" + "16" + "401288888888188X",
+			ErrInvalidContent,
+		},
+		{
+			"should-return-error-for-trailing-data",
+			"0100" + "4000000000000000" + "164012888888881881" + "00",
+			ErrTrailingData,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewSpec(EncodingASCII).Unpack([]byte(c.data))
+			assert.ErrorIs(t, err, c.expectError)
+		})
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	msg := newTestMessage()
+	msg.Fields[FieldExpiry] = "251"
+	_, err := NewSpec(EncodingBCD).Pack(msg)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+
+	msg = newTestMessage()
+	msg.Fields[48] = "data"
+	_, err = NewSpec(EncodingBCD).Pack(msg)
+	assert.ErrorIs(t, err, ErrUnsupportedField)
+}
+
+func TestMessageCreditCard(t *testing.T) {
+	msg, err := NewSpec(EncodingASCII).Unpack([]byte("0200" + "4004000000000000" + "165105105105105100" + "2512"))
+	assert.NoError(t, err)
+
+	expiry, ok := msg.Expiry()
+	assert.True(t, ok)
+	assert.Equal(t, "2512", expiry)
+
+	creditCard, err := msg.CreditCard()
+	assert.NoError(t, err)
+	assert.True(t, creditCard.Valid())
+	assert.Equal(t, pkg.SchemaMasterCard, creditCard.Schema())
+
+	_, err = NewMessage("0200").CreditCard()
+	assert.ErrorIs(t, err, ErrMissingField)
+	assert.EqualError(t, err, "field 2: invalid iso8583 message: field is missing")
+}
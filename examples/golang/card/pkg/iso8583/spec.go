@@ -0,0 +1,144 @@
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Encoding defines how numeric data, length prefixes and bitmaps travel on the wire.
+type Encoding int
+
+const (
+	// EncodingASCII sends digits as characters and bitmaps as hex characters.
+	EncodingASCII Encoding = iota
+	// EncodingBCD packs two digits per byte and sends bitmaps as raw bytes.
+	EncodingBCD
+)
+
+// LengthType tells whether a field has a fixed size or a length prefix.
+type LengthType int
+
+const (
+	Fixed  LengthType = iota
+	LLVAR             // up to 99 characters, two digit length prefix
+	LLLVAR            // up to 999 characters, three digit length prefix
+)
+
+// FieldType restricts the characters a field may contain.
+type FieldType int
+
+const (
+	// Numeric fields (n) contain digits only.
+	Numeric FieldType = iota
+	// Track2 fields (z) contain digits and the '=' field separator.
+	Track2
+)
+
+const (
+	FieldSecondaryBitmap = 1
+	FieldPAN             = 2
+	FieldExpiry          = 14
+	FieldPOSEntryMode    = 22
+	FieldTrack2          = 35
+
+	maxField = 128
+)
+
+var (
+	ErrTruncated        = errors.New("invalid iso8583 message: truncated input")
+	ErrTrailingData     = errors.New("invalid iso8583 message: trailing data")
+	ErrUnsupportedField = errors.New("invalid iso8583 message: field is not in the specification")
+	ErrInvalidLength    = errors.New("invalid iso8583 message: field length out of range")
+	ErrInvalidContent   = errors.New("invalid iso8583 message: field contains invalid characters")
+	ErrInvalidMTI       = errors.New("invalid iso8583 message: malformed mti")
+	ErrMissingField     = errors.New("invalid iso8583 message: field is missing")
+)
+
+// FieldSpec describes how a single data element is encoded.
+// Length is the exact size of Fixed fields and the maximum size of LLVAR/LLLVAR fields,
+// counted in characters (digits), not bytes.
+type FieldSpec struct {
+	Type       FieldType
+	LengthType LengthType
+	Length     int
+	Encoding   Encoding
+}
+
+// Spec drives both the parser and the builder.
+// Fields not listed in Fields are rejected, since their size cannot be known.
+type Spec struct {
+	MTI    Encoding
+	Bitmap Encoding
+	Fields map[int]FieldSpec
+}
+
+// NewSpec returns the specification of the fields needed to validate card data
+// (DE2, DE14, DE22 and DE35) with every element using the same encoding.
+// More fields can be added to Spec.Fields before use.
+func NewSpec(encoding Encoding) *Spec {
+	return &Spec{
+		MTI:    encoding,
+		Bitmap: encoding,
+		Fields: map[int]FieldSpec{
+			FieldPAN:          {Type: Numeric, LengthType: LLVAR, Length: 19, Encoding: encoding},
+			FieldExpiry:       {Type: Numeric, LengthType: Fixed, Length: 4, Encoding: encoding},
+			FieldPOSEntryMode: {Type: Numeric, LengthType: Fixed, Length: 3, Encoding: encoding},
+			FieldTrack2:       {Type: Track2, LengthType: LLVAR, Length: 37, Encoding: encoding},
+		},
+	}
+}
+
+func (fs FieldSpec) validate(field int, value string) error {
+	size := len(value)
+	if fs.LengthType == Fixed {
+		if size != fs.Length {
+			return fmt.Errorf("field %d: %w", field, ErrInvalidLength)
+		}
+	} else if size > fs.Length || size > fs.LengthType.max() {
+		return fmt.Errorf("field %d: %w", field, ErrInvalidLength)
+	}
+
+	for i := 0; i < size; i++ {
+		ch := value[i]
+		if (ch < '0' || ch > '9') && !(fs.Type == Track2 && ch == '=') {
+			return fmt.Errorf("field %d: %w", field, ErrInvalidContent)
+		}
+	}
+	return nil
+}
+
+// digits returns the number of digits in the length prefix.
+func (lt LengthType) digits() int {
+	switch lt {
+	case LLVAR:
+		return 2
+	case LLLVAR:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (lt LengthType) max() int {
+	switch lt {
+	case LLVAR:
+		return 99
+	case LLLVAR:
+		return 999
+	default:
+		return 0
+	}
+}
+
+func formatLength(length, digits int) string {
+	return fmt.Sprintf("%0*d", digits, length)
+}
+
+func parseLength(s string) (int, error) {
+	length, err := strconv.Atoi(s)
+	if err != nil || length < 0 {
+		return 0, ErrInvalidLength
+	}
+	return length, nil
+}
@@ -0,0 +1,50 @@
+package issuer
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"math/rand/v2"
+)
+
+type Allocation int
+
+const (
+	// AllocateSequential hands out account numbers in ascending order.
+	AllocateSequential Allocation = iota
+	// AllocatePermuted spreads account numbers over the range, so issued cards don't look enumerated.
+	AllocatePermuted
+)
+
+// allocator maps the n-th allocation of a range to an account number below size.
+// It is a bijection on [0, size), so distinct indexes never collide.
+type allocator struct {
+	size uint64
+	a, c uint64
+}
+
+// newAllocator derives the permutation from the seed and the range, the same configuration
+// yields the same sequence after a restart.
+func newAllocator(allocation Allocation, size uint64, seed uint64, key string) allocator {
+	if allocation == AllocateSequential {
+		return allocator{size: size, a: 1}
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	r := rand.New(rand.NewPCG(seed, h.Sum64()))
+
+	// x -> a*x + c mod 10^k is a permutation when a is coprime with 10^k, i.e. neither even nor a multiple of 5.
+	a := r.Uint64N(size)
+	for a%2 == 0 || a%5 == 0 {
+		a = (a + 1) % size
+	}
+	return allocator{size: size, a: a, c: r.Uint64N(size)}
+}
+
+func (al allocator) account(index uint64) uint64 {
+	// The product can exceed 64 bits for 12 digit account numbers.
+	hi, lo := bits.Mul64(al.a, index)
+	lo, carry := bits.Add64(lo, al.c, 0)
+	hi += carry
+	return bits.Rem64(hi, lo, al.size)
+}
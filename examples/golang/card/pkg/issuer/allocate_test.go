@@ -0,0 +1,45 @@
+//go:build unit
+
+package issuer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocatorIsPermutation(t *testing.T) {
+	cases := []struct {
+		name       string
+		allocation Allocation
+		size       uint64
+	}{
+		{"should-allocate-sequentially", AllocateSequential, 1000},
+		{"should-permute-single-digit-range", AllocatePermuted, 10},
+		{"should-permute-four-digit-range", AllocatePermuted, 10000},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			al := newAllocator(c.allocation, c.size, 42, "411111/16")
+			seen := make(map[uint64]bool, c.size)
+			for i := uint64(0); i < c.size; i++ {
+				account := al.account(i)
+				assert.Less(t, account, c.size)
+				assert.False(t, seen[account], "account %d allocated twice", account)
+				seen[account] = true
+			}
+		})
+	}
+}
+
+func TestAllocatorIsStable(t *testing.T) {
+	first := newAllocator(AllocatePermuted, 1e12, 7, "401288/19")
+	second := newAllocator(AllocatePermuted, 1e12, 7, "401288/19")
+	other := newAllocator(AllocatePermuted, 1e12, 8, "401288/19")
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+	// Indexes close to the range size must not overflow.
+	assert.Less(t, first.account(1e12-1), uint64(1e12))
+}
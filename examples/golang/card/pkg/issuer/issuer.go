@@ -0,0 +1,395 @@
+package issuer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"card/pkg"
+	"card/pkg/card"
+	"card/pkg/checkdigit"
+	"card/pkg/utils"
+)
+
+var (
+	ErrNoRange           = errors.New("issuer: no range configured for schema")
+	ErrRangeExhausted    = errors.New("issuer: all account numbers of the range are issued")
+	ErrNotFound          = errors.New("issuer: card not found")
+	ErrInvalidTransition = errors.New("issuer: invalid state transition")
+)
+
+type State string
+
+const (
+	StateActive  State = "active"
+	StateBlocked State = "blocked"
+	StateExpired State = "expired"
+)
+
+// Range is a BIN range cards of a schema are issued from.
+type Range struct {
+	Schema pkg.Schema
+	BIN    string // Leading digits shared by all cards of the range.
+	Length int    // Length of the card numbers, including BIN and check digit.
+}
+
+// Card is an issued virtual card. The issuer keeps clear PANs, it is meant for test environments only.
+type Card struct {
+	Token       string     `json:"token"`
+	Fingerprint string     `json:"fingerprint"`
+	Schema      pkg.Schema `json:"schema"`
+	PAN         string     `json:"pan"`
+	Expiry      string     `json:"expiry"` // YYMM, like ISO 8583 DE14.
+	CVV         string     `json:"cvv"`
+	State       State      `json:"state"`
+	IssuedAt    time.Time  `json:"issuedAt"`
+}
+
+type Config struct {
+	Ranges     []Range
+	Allocation Allocation
+	Seed       uint64 // Seeds the permutation of AllocatePermuted.
+
+	// FingerprintKey keys card.Fingerprint, use the key of the services looking cards up.
+	FingerprintKey []byte
+	// Validity is the time from issuance to expiry, 3 years when zero.
+	Validity time.Duration
+}
+
+// Issuer issues virtual cards from the configured ranges and keeps track of their state.
+// It is safe for concurrent use.
+type Issuer struct {
+	cfg        Config
+	store      Store
+	random     io.Reader
+	clock      func() time.Time
+	allocators map[string]allocator
+	luhn       *checkdigit.LuhnModN
+
+	mu            sync.Mutex
+	ledger        *Ledger
+	byToken       map[string]int // Index into ledger.Cards.
+	byFingerprint map[string]int
+	byPAN         map[string]int // Independent of the fingerprint key, which may be rotated.
+}
+
+type Option func(*Issuer)
+
+// WithRandom sets the source of tokens and CVVs, crypto/rand by default.
+func WithRandom(random io.Reader) Option {
+	return func(is *Issuer) {
+		is.random = random
+	}
+}
+
+// WithClock sets the source of issuance times, time.Now by default.
+func WithClock(clock func() time.Time) Option {
+	return func(is *Issuer) {
+		is.clock = clock
+	}
+}
+
+// NewIssuer validates the ranges and loads the ledger from the store.
+// Every number of a range must classify as its schema at the time of the issuer clock.
+func NewIssuer(cfg Config, store Store, opts ...Option) (*Issuer, error) {
+	if cfg.Validity == 0 {
+		cfg.Validity = 3 * 365 * 24 * time.Hour
+	}
+
+	is := &Issuer{
+		cfg:           cfg,
+		store:         store,
+		random:        rand.Reader,
+		clock:         time.Now,
+		allocators:    map[string]allocator{},
+		luhn:          checkdigit.NewLuhn(),
+		byToken:       map[string]int{},
+		byFingerprint: map[string]int{},
+		byPAN:         map[string]int{},
+	}
+	for _, o := range opts {
+		o(is)
+	}
+
+	for _, r := range cfg.Ranges {
+		if err := r.validate(is.clock()); err != nil {
+			return nil, err
+		}
+		size := uint64(1)
+		for i := 0; i < r.accountDigits(); i++ {
+			size *= 10
+		}
+		is.allocators[r.key()] = newAllocator(cfg.Allocation, size, cfg.Seed, r.key())
+	}
+
+	ledger, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	is.ledger = ledger
+	for i, c := range ledger.Cards {
+		is.byToken[c.Token] = i
+		is.byFingerprint[c.Fingerprint] = i
+		is.byPAN[c.PAN] = i
+	}
+	return is, nil
+}
+
+func (r Range) validate(at time.Time) error {
+	if r.Schema == "" {
+		return fmt.Errorf("issuer: range %q has no schema", r.BIN)
+	}
+	if r.BIN == "" || strings.Trim(r.BIN, "0123456789") != "" {
+		return fmt.Errorf("issuer: range %q is not numeric", r.BIN)
+	}
+	if r.Length < 12 || r.Length > 19 {
+		return fmt.Errorf("issuer: range %q length %d is not between 12 and 19", r.BIN, r.Length)
+	}
+	if r.accountDigits() < 1 {
+		return fmt.Errorf("issuer: range %q leaves no account digits at length %d", r.BIN, r.Length)
+	}
+	// Classifying the lowest and highest number covers the range, scheme ranges are contiguous.
+	for _, fill := range []string{"0", "9"} {
+		schema, err := utils.CardSchemaAt(r.BIN+strings.Repeat(fill, r.Length-len(r.BIN)), at)
+		if err != nil {
+			return err
+		}
+		if schema != r.Schema {
+			return fmt.Errorf("issuer: range %q at length %d is %s, not %s", r.BIN, r.Length, schema, r.Schema)
+		}
+	}
+	return nil
+}
+
+// accountDigits is the number of digits between BIN and check digit.
+func (r Range) accountDigits() int {
+	return r.Length - len(r.BIN) - 1
+}
+
+func (r Range) key() string {
+	return r.BIN + "/" + strconv.Itoa(r.Length)
+}
+
+// Issue allocates a card number from the first range of the schema with numbers left.
+func (is *Issuer) Issue(schema pkg.Schema) (Card, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	found := false
+	for _, r := range is.cfg.Ranges {
+		if r.Schema != schema {
+			continue
+		}
+		found = true
+
+		pan, fingerprint, next, ok, err := is.allocate(r)
+		if err != nil {
+			return Card{}, err
+		} else if !ok {
+			continue
+		}
+		return is.issue(r, pan, fingerprint, next)
+	}
+
+	if !found {
+		return Card{}, fmt.Errorf("%w: %s", ErrNoRange, schema)
+	}
+	return Card{}, fmt.Errorf("%w: %s", ErrRangeExhausted, schema)
+}
+
+// allocate returns the next free card number of the range and the allocation index following it,
+// which issue stores with the card. Numbers already issued, e.g. from an overlapping range or
+// under another seed, are skipped.
+func (is *Issuer) allocate(r Range) (string, string, uint64, bool, error) {
+	al := is.allocators[r.key()]
+	for next := is.ledger.Next[r.key()]; next < al.size; next++ {
+		account := fmt.Sprintf("%0*d", r.accountDigits(), al.account(next))
+		check, err := is.luhn.Compute(r.BIN + account)
+		if err != nil {
+			return "", "", 0, false, err
+		}
+		pan := r.BIN + account + check
+		if _, issued := is.byPAN[pan]; issued {
+			continue
+		}
+
+		creditCard, err := card.NewCreditCard(pan, card.WithChecksumPolicy(card.ChecksumStrict))
+		if err != nil {
+			return "", "", 0, false, err
+		}
+		return pan, card.Fingerprint(is.cfg.FingerprintKey, creditCard), next + 1, true, nil
+	}
+	return "", "", 0, false, nil
+}
+
+func (is *Issuer) issue(r Range, pan, fingerprint string, next uint64) (Card, error) {
+	token, err := is.token()
+	if err != nil {
+		return Card{}, err
+	}
+	// American Express prints a four digit CID instead of the three digit CVV.
+	cvvDigits := 3
+	if r.Schema == pkg.SchemaAmericanExpress {
+		cvvDigits = 4
+	}
+	cvv, err := is.digits(cvvDigits)
+	if err != nil {
+		return Card{}, err
+	}
+
+	now := is.clock()
+	c := Card{
+		Token:       token,
+		Fingerprint: fingerprint,
+		Schema:      r.Schema,
+		PAN:         pan,
+		Expiry:      now.Add(is.cfg.Validity).Format("0601"),
+		CVV:         cvv,
+		State:       StateActive,
+		IssuedAt:    now,
+	}
+
+	previous, hadPrevious := is.ledger.Next[r.key()]
+	is.ledger.Next[r.key()] = next
+	is.ledger.Cards = append(is.ledger.Cards, c)
+	if err := is.store.Save(is.ledger); err != nil {
+		is.ledger.Cards = is.ledger.Cards[:len(is.ledger.Cards)-1]
+		if hadPrevious {
+			is.ledger.Next[r.key()] = previous
+		} else {
+			delete(is.ledger.Next, r.key())
+		}
+		return Card{}, err
+	}
+	is.byToken[c.Token] = len(is.ledger.Cards) - 1
+	is.byFingerprint[c.Fingerprint] = len(is.ledger.Cards) - 1
+	is.byPAN[c.PAN] = len(is.ledger.Cards) - 1
+	return c, nil
+}
+
+func (is *Issuer) token() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(is.random, b); err != nil {
+		return "", err
+	}
+	return "tok_" + hex.EncodeToString(b), nil
+}
+
+func (is *Issuer) digits(n int) (string, error) {
+	v, err := rand.Int(is.random, big.NewInt(int64(pow10(n))))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", n, v.Int64()), nil
+}
+
+func pow10(n int) int {
+	p := 1
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// ByToken returns the card issued under the token.
+func (is *Issuer) ByToken(token string) (Card, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	i, ok := is.byToken[token]
+	if !ok {
+		return Card{}, ErrNotFound
+	}
+	return is.ledger.Cards[i], nil
+}
+
+// ByFingerprint returns the card with the card.Fingerprint under the configured key.
+func (is *Issuer) ByFingerprint(fingerprint string) (Card, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	i, ok := is.byFingerprint[fingerprint]
+	if !ok {
+		return Card{}, ErrNotFound
+	}
+	return is.ledger.Cards[i], nil
+}
+
+// Block moves an active card to blocked.
+func (is *Issuer) Block(token string) (Card, error) {
+	return is.transition(token, StateBlocked, StateActive)
+}
+
+// Expire moves an active or blocked card to expired, which is final.
+func (is *Issuer) Expire(token string) (Card, error) {
+	return is.transition(token, StateExpired, StateActive, StateBlocked)
+}
+
+// ExpireDue expires all cards past the end of their expiry month and returns how many.
+func (is *Issuer) ExpireDue() (int, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	// The ledger only changes once saved, like on issuance.
+	now := is.clock()
+	cards := slices.Clone(is.ledger.Cards)
+	expired := 0
+	for i, c := range cards {
+		if c.State == StateExpired {
+			continue
+		}
+		expiry, err := time.Parse("0601", c.Expiry)
+		if err != nil {
+			return expired, fmt.Errorf("issuer: card %s: invalid expiry %q", c.Token, c.Expiry)
+		}
+		// Cards are valid through the last day of the expiry month.
+		if !now.Before(expiry.AddDate(0, 1, 0)) {
+			cards[i].State = StateExpired
+			expired++
+		}
+	}
+	if expired == 0 {
+		return 0, nil
+	}
+
+	ledger := &Ledger{Next: is.ledger.Next, Cards: cards}
+	if err := is.store.Save(ledger); err != nil {
+		return 0, err
+	}
+	is.ledger = ledger
+	return expired, nil
+}
+
+func (is *Issuer) transition(token string, to State, from ...State) (Card, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	i, ok := is.byToken[token]
+	if !ok {
+		return Card{}, ErrNotFound
+	}
+	c := &is.ledger.Cards[i]
+	allowed := false
+	for _, state := range from {
+		allowed = allowed || c.State == state
+	}
+	if !allowed {
+		return Card{}, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, c.State, to)
+	}
+
+	previous := c.State
+	c.State = to
+	if err := is.store.Save(is.ledger); err != nil {
+		c.State = previous
+		return Card{}, err
+	}
+	return *c, nil
+}
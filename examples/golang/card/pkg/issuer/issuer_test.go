@@ -0,0 +1,335 @@
+//go:build unit
+
+package issuer
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"card/pkg"
+	"card/pkg/card"
+	"card/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = []byte("test-key")
+
+func newTestIssuer(t *testing.T, cfg Config, store Store, now *time.Time) *Issuer {
+	t.Helper()
+	cfg.FingerprintKey = testKey
+	is, err := NewIssuer(cfg, store,
+		WithRandom(bytes.NewReader(bytes.Repeat([]byte{0x5a, 0x17, 0xc3}, 4096))),
+		WithClock(func() time.Time { return *now }),
+	)
+	assert.NoError(t, err)
+	return is
+}
+
+func TestIssue(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	cfg := Config{
+		Ranges: []Range{
+			{Schema: pkg.SchemaVisa, BIN: "411111", Length: 16},
+			{Schema: pkg.SchemaAmericanExpress, BIN: "3782", Length: 15},
+		},
+		Allocation: AllocatePermuted,
+		Seed:       1,
+	}
+	is := newTestIssuer(t, cfg, NewFileStore(filepath.Join(t.TempDir(), "cards.json")), &now)
+
+	cases := []struct {
+		name           string
+		schema         pkg.Schema
+		expectedLength int
+		expectedCVV    int
+	}{
+		{"should-issue-visa-card", pkg.SchemaVisa, 16, 3},
+		{"should-issue-american-express-card-with-cid", pkg.SchemaAmericanExpress, 15, 4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			issued, err := is.Issue(c.schema)
+			assert.NoError(t, err)
+			assert.Len(t, issued.PAN, c.expectedLength)
+			assert.Len(t, issued.CVV, c.expectedCVV)
+			assert.Equal(t, "2706", issued.Expiry)
+			assert.Equal(t, StateActive, issued.State)
+
+			valid, err := utils.CardValid(issued.PAN)
+			assert.NoError(t, err)
+			assert.True(t, valid)
+			schema, err := utils.CardSchema(issued.PAN)
+			assert.NoError(t, err)
+			assert.Equal(t, c.schema, schema)
+		})
+	}
+}
+
+func TestIssueErrors(t *testing.T) {
+	now := time.Now()
+	cfg := Config{Ranges: []Range{{Schema: pkg.SchemaMasterCard, BIN: "51051051051051", Length: 16}}}
+	is := newTestIssuer(t, cfg, NewFileStore(filepath.Join(t.TempDir(), "cards.json")), &now)
+
+	_, err := is.Issue(pkg.SchemaVisa)
+	assert.ErrorIs(t, err, ErrNoRange)
+
+	// A single account digit allows ten cards.
+	pans := map[string]bool{}
+	for i := 0; i < 10; i++ {
+		issued, err := is.Issue(pkg.SchemaMasterCard)
+		assert.NoError(t, err)
+		pans[issued.PAN] = true
+	}
+	assert.Len(t, pans, 10)
+
+	_, err = is.Issue(pkg.SchemaMasterCard)
+	assert.ErrorIs(t, err, ErrRangeExhausted)
+}
+
+func TestIssueSkipsCardsOfOverlappingRanges(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "cards.json")
+	wide := Config{Ranges: []Range{{Schema: pkg.SchemaVisa, BIN: "4111111111111", Length: 16}}}
+	is := newTestIssuer(t, wide, NewFileStore(path), &now)
+	for i := 0; i < 100; i++ {
+		_, err := is.Issue(pkg.SchemaVisa)
+		assert.NoError(t, err)
+	}
+
+	// The narrower range lies within the numbers already issued from the wider one.
+	narrow := Config{Ranges: []Range{{Schema: pkg.SchemaVisa, BIN: "41111111111110", Length: 16}}}
+	is = newTestIssuer(t, narrow, NewFileStore(path), &now)
+	_, err := is.Issue(pkg.SchemaVisa)
+	assert.ErrorIs(t, err, ErrRangeExhausted)
+}
+
+func TestIssueSkipsCardsIssuedUnderRotatedKey(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "cards.json")
+	wide := Config{Ranges: []Range{{Schema: pkg.SchemaVisa, BIN: "4111111111111", Length: 16}}}
+	is := newTestIssuer(t, wide, NewFileStore(path), &now)
+	for i := 0; i < 100; i++ {
+		_, err := is.Issue(pkg.SchemaVisa)
+		assert.NoError(t, err)
+	}
+
+	// The issued numbers were fingerprinted under the previous key.
+	narrow := Config{
+		Ranges:         []Range{{Schema: pkg.SchemaVisa, BIN: "41111111111110", Length: 16}},
+		FingerprintKey: []byte("rotated-key"),
+	}
+	is, err := NewIssuer(narrow, NewFileStore(path), WithClock(func() time.Time { return now }))
+	assert.NoError(t, err)
+	_, err = is.Issue(pkg.SchemaVisa)
+	assert.ErrorIs(t, err, ErrRangeExhausted)
+}
+
+func TestLookupAfterRestart(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "cards.json")
+	cfg := Config{
+		Ranges:     []Range{{Schema: pkg.SchemaVisa, BIN: "401288", Length: 16}},
+		Allocation: AllocateSequential,
+	}
+
+	is := newTestIssuer(t, cfg, NewFileStore(path), &now)
+	issued, err := is.Issue(pkg.SchemaVisa)
+	assert.NoError(t, err)
+	assert.Equal(t, "4012880000000003", issued.PAN)
+
+	is = newTestIssuer(t, cfg, NewFileStore(path), &now)
+	byToken, err := is.ByToken(issued.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, issued.PAN, byToken.PAN)
+
+	creditCard, err := card.NewCreditCard(issued.PAN)
+	assert.NoError(t, err)
+	byFingerprint, err := is.ByFingerprint(card.Fingerprint(testKey, creditCard))
+	assert.NoError(t, err)
+	assert.Equal(t, issued.Token, byFingerprint.Token)
+
+	// Allocation continues where it stopped.
+	next, err := is.Issue(pkg.SchemaVisa)
+	assert.NoError(t, err)
+	assert.Equal(t, "4012880000000011", next.PAN)
+
+	_, err = is.ByToken("tok_unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTransitions(t *testing.T) {
+	cases := []struct {
+		name          string
+		transitions   []func(*Issuer, string) (Card, error)
+		expectedState State
+		expectedError error
+	}{
+		{
+			"should-block-active-card",
+			[]func(*Issuer, string) (Card, error){(*Issuer).Block},
+			StateBlocked,
+			nil,
+		},
+		{
+			"should-expire-blocked-card",
+			[]func(*Issuer, string) (Card, error){(*Issuer).Block, (*Issuer).Expire},
+			StateExpired,
+			nil,
+		},
+		{
+			"should-not-block-blocked-card",
+			[]func(*Issuer, string) (Card, error){(*Issuer).Block, (*Issuer).Block},
+			StateBlocked,
+			ErrInvalidTransition,
+		},
+		{
+			"should-not-block-expired-card",
+			[]func(*Issuer, string) (Card, error){(*Issuer).Expire, (*Issuer).Block},
+			StateExpired,
+			ErrInvalidTransition,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			now := time.Now()
+			cfg := Config{Ranges: []Range{{Schema: pkg.SchemaVisa, BIN: "401288", Length: 16}}}
+			is := newTestIssuer(t, cfg, NewFileStore(filepath.Join(t.TempDir(), "cards.json")), &now)
+			issued, err := is.Issue(pkg.SchemaVisa)
+			assert.NoError(t, err)
+
+			for _, transition := range c.transitions {
+				_, err = transition(is, issued.Token)
+			}
+			assert.ErrorIs(t, err, c.expectedError)
+
+			stored, err := is.ByToken(issued.Token)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedState, stored.State)
+		})
+	}
+}
+
+func TestExpireDue(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	cfg := Config{
+		Ranges:   []Range{{Schema: pkg.SchemaVisa, BIN: "401288", Length: 16}},
+		Validity: 24 * time.Hour,
+	}
+	is := newTestIssuer(t, cfg, NewFileStore(filepath.Join(t.TempDir(), "cards.json")), &now)
+	issued, err := is.Issue(pkg.SchemaVisa)
+	assert.NoError(t, err)
+	assert.Equal(t, "2406", issued.Expiry)
+
+	now = time.Date(2024, 6, 30, 23, 59, 0, 0, time.UTC)
+	expired, err := is.ExpireDue()
+	assert.NoError(t, err)
+	assert.Zero(t, expired)
+
+	now = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	expired, err = is.ExpireDue()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	stored, err := is.ByToken(issued.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, StateExpired, stored.State)
+}
+
+func TestNewIssuerRejectsInvalidRanges(t *testing.T) {
+	cases := []struct {
+		name string
+		rng  Range
+	}{
+		{"should-reject-missing-schema", Range{BIN: "4", Length: 16}},
+		{"should-reject-non-numeric-bin", Range{Schema: pkg.SchemaVisa, BIN: "4x", Length: 16}},
+		{"should-reject-too-long-numbers", Range{Schema: pkg.SchemaVisa, BIN: "4", Length: 20}},
+		{"should-reject-range-without-account-digits", Range{Schema: pkg.SchemaVisa, BIN: "401288888888", Length: 13}},
+		{"should-reject-bin-of-other-schema", Range{Schema: pkg.SchemaVisa, BIN: "510510", Length: 16}},
+		{"should-reject-length-of-other-schema", Range{Schema: pkg.SchemaVisa, BIN: "401288", Length: 15}},
+		{"should-reject-bin-spanning-schemes", Range{Schema: pkg.SchemaJCB, BIN: "35", Length: 16}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewIssuer(Config{Ranges: []Range{c.rng}}, NewFileStore(filepath.Join(t.TempDir(), "cards.json")))
+			assert.Error(t, err)
+		})
+	}
+}
+
+type failingStore struct {
+	*FileStore
+}
+
+func (failingStore) Save(*Ledger) error {
+	return errors.New("disk full")
+}
+
+func TestIssueKeepsStateOnSaveFailure(t *testing.T) {
+	now := time.Now()
+	cfg := Config{Ranges: []Range{{Schema: pkg.SchemaVisa, BIN: "401288", Length: 16}}}
+	is := newTestIssuer(t, cfg, failingStore{NewFileStore(filepath.Join(t.TempDir(), "cards.json"))}, &now)
+
+	_, err := is.Issue(pkg.SchemaVisa)
+	assert.EqualError(t, err, "disk full")
+	assert.Empty(t, is.ledger.Cards)
+	assert.Empty(t, is.ledger.Next)
+}
+
+type flakyStore struct {
+	*FileStore
+	fail bool
+}
+
+func (fs *flakyStore) Save(ledger *Ledger) error {
+	if fs.fail {
+		return errors.New("disk full")
+	}
+	return fs.FileStore.Save(ledger)
+}
+
+func TestIssueRetriesNumberAfterSaveFailure(t *testing.T) {
+	now := time.Now()
+	cfg := Config{Ranges: []Range{{Schema: pkg.SchemaVisa, BIN: "401288", Length: 16}}}
+	store := &flakyStore{FileStore: NewFileStore(filepath.Join(t.TempDir(), "cards.json"))}
+	is := newTestIssuer(t, cfg, store, &now)
+
+	first, err := is.Issue(pkg.SchemaVisa)
+	assert.NoError(t, err)
+	store.fail = true
+	_, err = is.Issue(pkg.SchemaVisa)
+	assert.EqualError(t, err, "disk full")
+	assert.Equal(t, uint64(1), is.ledger.Next["401288/16"])
+
+	store.fail = false
+	second, err := is.Issue(pkg.SchemaVisa)
+	assert.NoError(t, err)
+	assert.Equal(t, "4012880000000003", first.PAN)
+	assert.Equal(t, "4012880000000011", second.PAN)
+}
+
+func TestExpireDueKeepsStateOnSaveFailure(t *testing.T) {
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "cards.json")
+	cfg := Config{
+		Ranges:   []Range{{Schema: pkg.SchemaVisa, BIN: "401288", Length: 16}},
+		Validity: 24 * time.Hour,
+	}
+	issued, err := newTestIssuer(t, cfg, NewFileStore(path), &now).Issue(pkg.SchemaVisa)
+	assert.NoError(t, err)
+
+	is := newTestIssuer(t, cfg, failingStore{NewFileStore(path)}, &now)
+	now = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	_, err = is.ExpireDue()
+	assert.EqualError(t, err, "disk full")
+
+	stored, err := is.ByToken(issued.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, StateActive, stored.State)
+}
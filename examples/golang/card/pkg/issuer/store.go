@@ -0,0 +1,71 @@
+package issuer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Ledger is the persistent state of an issuer.
+type Ledger struct {
+	Next  map[string]uint64 `json:"next"` // Next allocation index per range key.
+	Cards []Card            `json:"cards"`
+}
+
+// Store persists the ledger. Save is called after every change while the issuer holds its lock.
+type Store interface {
+	Load() (*Ledger, error)
+	Save(*Ledger) error
+}
+
+// FileStore keeps the ledger in a JSON file.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load returns an empty ledger when the file does not exist yet.
+func (s *FileStore) Load() (*Ledger, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Ledger{Next: map[string]uint64{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var ledger Ledger
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, err
+	}
+	if ledger.Next == nil {
+		ledger.Next = map[string]uint64{}
+	}
+	return &ledger, nil
+}
+
+// Save replaces the file atomically, a crash never leaves a truncated ledger behind.
+func (s *FileStore) Save(ledger *Ledger) error {
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Set holds named policies, usually one per merchant.
+type Set map[string]*Policy
+
+type config struct {
+	Policies map[string]*Policy `json:"policies"`
+}
+
+// LoadFile reads a policy set from a JSON file:
+//
+//	{"policies": {"merchant-a": {"default": "deny", "rules": [{"name": "visa", "action": "allow", "schemas": ["Visa"]}]}}}
+func LoadFile(path string) (Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load reads a policy set from JSON and validates every policy in it.
+func Load(r io.Reader) (Set, error) {
+	var cfg config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid policy config: %w", err)
+	}
+
+	set := make(Set, len(cfg.Policies))
+	for name, p := range cfg.Policies {
+		if p == nil {
+			return nil, fmt.Errorf("invalid policy config: policy %q is empty", name)
+		}
+		p.Name = name
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid policy config: %w", err)
+		}
+		set[name] = p
+	}
+	return set, nil
+}
+
+// Get returns the named policy.
+func (s Set) Get(name string) (*Policy, error) {
+	p, ok := s[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q", name)
+	}
+	return p, nil
+}
+
+// Names returns the policy names in sorted order.
+func (s Set) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
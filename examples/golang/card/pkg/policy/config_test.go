@@ -0,0 +1,77 @@
+//go:build unit
+
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	set, err := Load(strings.NewReader(`{
+		"policies": {
+			"merchant-b": {"default": "allow", "rules": []},
+			"merchant-a": {
+				"default": "deny",
+				"rules": [{"name": "visa", "action": "allow", "schemas": ["Visa"], "bin_ranges": ["4"], "lengths": [16]}]
+			}
+		}
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"merchant-a", "merchant-b"}, set.Names())
+
+	p, err := set.Get("merchant-a")
+	assert.NoError(t, err)
+	assert.Equal(t, "merchant-a", p.Name)
+	assert.Equal(t, []pkg.Schema{pkg.SchemaVisa}, p.Rules[0].Schemas)
+
+	_, err = set.Get("merchant-c")
+	assert.Error(t, err)
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+	}{
+		{
+			"should-return-error-for-malformed-json",
+			`{"policies": `,
+		},
+		{
+			"should-return-error-for-unknown-field",
+			`{"policies": {"m": {"default": "deny", "rulez": []}}}`,
+		},
+		{
+			"should-return-error-for-empty-policy",
+			`{"policies": {"m": null}}`,
+		},
+		{
+			"should-return-error-for-unknown-default",
+			`{"policies": {"m": {"default": "maybe"}}}`,
+		},
+		{
+			"should-return-error-for-unnamed-rule",
+			`{"policies": {"m": {"default": "deny", "rules": [{"action": "allow"}]}}}`,
+		},
+		{
+			"should-return-error-for-unknown-action",
+			`{"policies": {"m": {"default": "deny", "rules": [{"name": "r", "action": "accept"}]}}}`,
+		},
+		{
+			"should-return-error-for-malformed-bin-range",
+			`{"policies": {"m": {"default": "deny", "rules": [{"name": "r", "action": "allow", "bin_ranges": ["9-1"]}]}}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(c.config))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"card/pkg"
+	"card/pkg/card"
+	"card/pkg/utils"
+)
+
+type Action string
+
+const (
+	ActionAllow Action = "allow"
+	ActionDeny  Action = "deny"
+)
+
+// DefaultRule names the decision taken when no rule matched.
+const DefaultRule = "default"
+
+// Rule matches a card when every criterion that is set matches (an empty rule matches any card).
+// Country and funding criteria need BIN data, e.g. from utils.LoadBINFile, without it such a rule never matches.
+type Rule struct {
+	Name      string        `json:"name"`
+	Action    Action        `json:"action"`
+	Schemas   []pkg.Schema  `json:"schemas,omitempty"`
+	BINRanges []string      `json:"bin_ranges,omitempty"` // Prefixes like "4", "510000-559999".
+	Countries []string      `json:"countries,omitempty"`
+	Funding   []pkg.Funding `json:"funding,omitempty"`
+	Lengths   []int         `json:"lengths,omitempty"`
+}
+
+// Policy is an ordered rule list, the first matching rule decides.
+type Policy struct {
+	Name    string `json:"-"`
+	Default Action `json:"default"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Decision is the outcome of a policy evaluation together with the rule that fired.
+type Decision struct {
+	Accepted bool   `json:"accepted"`
+	Rule     string `json:"rule"`
+}
+
+// Evaluate decides whether the card is accepted. Pass nil as info when no BIN data is known.
+// Evaluation only looks at the classification, checksum validity is the caller's concern.
+func (p *Policy) Evaluate(c card.CreditCard, info *pkg.BINInfo) Decision {
+	for _, rule := range p.Rules {
+		if rule.matches(c, info) {
+			return Decision{Accepted: rule.Action == ActionAllow, Rule: rule.Name}
+		}
+	}
+	return Decision{Accepted: p.Default == ActionAllow, Rule: DefaultRule}
+}
+
+func (r *Rule) matches(c card.CreditCard, info *pkg.BINInfo) bool {
+	if len(r.Schemas) > 0 && !slices.Contains(r.Schemas, c.Schema()) {
+		return false
+	}
+	if len(r.Lengths) > 0 && !slices.Contains(r.Lengths, len(c.Number())) {
+		return false
+	}
+	if len(r.BINRanges) > 0 && !r.matchesBIN(c.Number()) {
+		return false
+	}
+	if len(r.Countries) > 0 && (info == nil || !slices.ContainsFunc(r.Countries, func(country string) bool {
+		return strings.EqualFold(country, info.Country)
+	})) {
+		return false
+	}
+	if len(r.Funding) > 0 && (info == nil || !slices.Contains(r.Funding, info.Funding)) {
+		return false
+	}
+	return true
+}
+
+func (r *Rule) matchesBIN(number string) bool {
+	for _, binRange := range r.BINRanges {
+		// Ranges are checked when the policy is loaded, so an error means no match.
+		if ok, err := utils.MatchPrefix(number, binRange); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) validate() error {
+	if err := validateAction(p.Default); err != nil {
+		return fmt.Errorf("policy %q: default: %w", p.Name, err)
+	}
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("policy %q: rule #%d: missing name", p.Name, i+1)
+		}
+		if err := validateAction(rule.Action); err != nil {
+			return fmt.Errorf("policy %q: rule %q: %w", p.Name, rule.Name, err)
+		}
+		for _, binRange := range rule.BINRanges {
+			if !utils.ValidBINRange(binRange) {
+				return fmt.Errorf("policy %q: rule %q: malformed bin range %q", p.Name, rule.Name, binRange)
+			}
+		}
+	}
+	return nil
+}
+
+func validateAction(action Action) error {
+	if action != ActionAllow && action != ActionDeny {
+		return fmt.Errorf("unknown action %q", action)
+	}
+	return nil
+}
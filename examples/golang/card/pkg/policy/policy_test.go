@@ -0,0 +1,102 @@
+//go:build unit
+
+package policy
+
+import (
+	"testing"
+
+	"card/pkg"
+	"card/pkg/card"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	p := &Policy{
+		Name:    "merchant",
+		Default: ActionDeny,
+		Rules: []Rule{
+			{Name: "no-prepaid", Action: ActionDeny, Funding: []pkg.Funding{pkg.FundingPrepaid}},
+			{Name: "no-us-issuers", Action: ActionDeny, Countries: []string{"US"}},
+			{Name: "blocked-bin", Action: ActionDeny, BINRanges: []string{"401288"}},
+			{Name: "short-visa", Action: ActionDeny, Schemas: []pkg.Schema{pkg.SchemaVisa}, Lengths: []int{13}},
+			{Name: "visa-and-amex", Action: ActionAllow, Schemas: []pkg.Schema{pkg.SchemaVisa, pkg.SchemaAmericanExpress}},
+			{Name: "mastercard-2-series", Action: ActionAllow, BINRanges: []string{"222100-272099"}},
+		},
+	}
+
+	cases := []struct {
+		name             string
+		cardNumber       string
+		info             *pkg.BINInfo
+		expectedAccepted bool
+		expectedRule     string
+	}{
+		{
+			"should-accept-by-schema",
+			"4111111111111111",
+			nil,
+			true,
+			"visa-and-amex",
+		},
+		{
+			"should-decline-by-bin-range",
+			"4012888888881881",
+			nil,
+			false,
+			"blocked-bin",
+		},
+		{
+			"should-decline-by-length",
+			"4222222222222",
+			nil,
+			false,
+			"short-visa",
+		},
+		{
+			"should-accept-by-bin-range",
+			"2221000000000009",
+			nil,
+			true,
+			"mastercard-2-series",
+		},
+		{
+			"should-decline-by-funding",
+			"378282246310005",
+			&pkg.BINInfo{Country: "DE", Funding: pkg.FundingPrepaid},
+			false,
+			"no-prepaid",
+		},
+		{
+			"should-decline-by-country",
+			"378282246310005",
+			&pkg.BINInfo{Country: "us", Funding: pkg.FundingCredit},
+			false,
+			"no-us-issuers",
+		},
+		{
+			"should-skip-bin-data-rules-without-bin-data",
+			"378282246310005",
+			nil,
+			true,
+			"visa-and-amex",
+		},
+		{
+			"should-fall-back-to-default",
+			"5105105105105100",
+			nil,
+			false,
+			DefaultRule,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			creditCard, err := card.NewCreditCard(c.cardNumber)
+			assert.NoError(t, err)
+			decision := p.Evaluate(creditCard, c.info)
+			assert.Equal(t, c.expectedAccepted, decision.Accepted)
+			assert.Equal(t, c.expectedRule, decision.Rule)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"card/pkg"
+	"card/pkg/card"
+)
+
+var ErrNoNetwork = errors.New("routing: no candidate network")
+
+// CostTier prices transactions up to an amount. Amounts and fees are in minor units.
+type CostTier struct {
+	UpTo        int64 `json:"up_to"` // Inclusive upper bound, 0 for no bound.
+	Fixed       int64 `json:"fixed"`
+	BasisPoints int64 `json:"basis_points"` // Hundredths of a percent of the amount.
+}
+
+// Mandate forces a network for cards matching all criteria that are set, such as regulated debit
+// cards which must be offered to a domestic network.
+type Mandate struct {
+	Name          string        `json:"name"`
+	Network       pkg.Schema    `json:"network"`
+	Countries     []string      `json:"countries,omitempty"`
+	Funding       []pkg.Funding `json:"funding,omitempty"`
+	RegulatedOnly bool          `json:"regulated_only,omitempty"`
+}
+
+// Config is the rule set of an Engine. Rules apply in this order: a matching mandate puts its network
+// first, then the networks listed in Preference follow in that order, then the remaining networks
+// by ascending cost, then those without costs in candidate order.
+type Config struct {
+	Mandates   []Mandate                 `json:"mandates,omitempty"`
+	Preference []pkg.Schema              `json:"preference,omitempty"`
+	Costs      map[pkg.Schema][]CostTier `json:"costs,omitempty"`
+}
+
+// Request describes a transaction to route.
+type Request struct {
+	Card       card.CreditCard
+	BIN        *pkg.BINInfo // Nil when no BIN data is known, mandates never match then.
+	Amount     int64        // In minor units.
+	Candidates []pkg.Schema // Networks able to process the card, derived from the card and BIN data when nil.
+}
+
+// Route is a network in routing order with the reason for its position.
+type Route struct {
+	Network   pkg.Schema `json:"network"`
+	Cost      int64      `json:"cost"` // Estimated fee in minor units, valid when CostKnown.
+	CostKnown bool       `json:"cost_known"`
+	Reason    string     `json:"reason"`
+}
+
+// Engine orders candidate networks by its rule set. It is read-only and safe for concurrent use.
+type Engine struct {
+	cfg Config
+}
+
+// NewEngine checks the cost tables, tiers must ascend with a single unbounded tier at the end.
+func NewEngine(cfg Config) (*Engine, error) {
+	for network, tiers := range cfg.Costs {
+		for i, tier := range tiers {
+			last := i == len(tiers)-1
+			switch {
+			case tier.UpTo == 0 && !last:
+				return nil, fmt.Errorf("routing: %s: unbounded cost tier must be the last one", network)
+			case i > 0 && tier.UpTo != 0 && tier.UpTo <= tiers[i-1].UpTo:
+				return nil, fmt.Errorf("routing: %s: cost tiers must ascend", network)
+			case tier.Fixed < 0 || tier.BasisPoints < 0:
+				return nil, fmt.Errorf("routing: %s: negative cost", network)
+			}
+		}
+	}
+	for _, m := range cfg.Mandates {
+		if m.Network == "" {
+			return nil, fmt.Errorf("routing: mandate %q has no network", m.Name)
+		}
+	}
+	return &Engine{cfg: cfg}, nil
+}
+
+// Route returns the candidate networks, best first.
+func (e *Engine) Route(req Request) ([]Route, error) {
+	candidates := candidates(req)
+	if len(candidates) == 0 {
+		return nil, ErrNoNetwork
+	}
+
+	routes := make([]Route, 0, len(candidates))
+	take := func(network pkg.Schema, reason string) {
+		route := Route{Network: network, Reason: reason}
+		route.Cost, route.CostKnown = e.cost(network, req.Amount)
+		routes = append(routes, route)
+		candidates = slices.DeleteFunc(candidates, func(s pkg.Schema) bool { return s == network })
+	}
+
+	for _, m := range e.cfg.Mandates {
+		if m.matches(req.BIN) && slices.Contains(candidates, m.Network) {
+			take(m.Network, fmt.Sprintf("mandated by %q", m.Name))
+			break
+		}
+	}
+
+	for i, network := range e.cfg.Preference {
+		if slices.Contains(candidates, network) {
+			take(network, fmt.Sprintf("merchant preference #%d", i+1))
+		}
+	}
+
+	var costed, uncosted []pkg.Schema
+	for _, network := range candidates {
+		if _, ok := e.cost(network, req.Amount); ok {
+			costed = append(costed, network)
+		} else {
+			uncosted = append(uncosted, network)
+		}
+	}
+	// Stable, so equal costs keep the candidate order.
+	sort.SliceStable(costed, func(i, j int) bool {
+		ci, _ := e.cost(costed[i], req.Amount)
+		cj, _ := e.cost(costed[j], req.Amount)
+		return ci < cj
+	})
+	for _, network := range costed {
+		cost, _ := e.cost(network, req.Amount)
+		take(network, fmt.Sprintf("cost %d for amount %d", cost, req.Amount))
+	}
+	for _, network := range uncosted {
+		take(network, "no preference or cost, candidate order")
+	}
+	return routes, nil
+}
+
+// candidates returns the requested networks or the card's schema followed by the co-badged networks
+// from BIN data, without duplicates. The card's schema is the one of the lookup it was validated with,
+// the built-in table is not consulted as it may disagree.
+func candidates(req Request) []pkg.Schema {
+	if req.Candidates != nil {
+		return dedupe(req.Candidates)
+	}
+
+	var networks []pkg.Schema
+	if req.Card != nil && req.Card.Schema() != pkg.SchemaUnknown {
+		networks = append(networks, req.Card.Schema())
+	}
+	if req.BIN != nil {
+		networks = append(networks, req.BIN.Networks...)
+	}
+	return dedupe(networks)
+}
+
+// dedupe returns a copy of the networks keeping the first occurrence of each.
+func dedupe(networks []pkg.Schema) []pkg.Schema {
+	unique := make([]pkg.Schema, 0, len(networks))
+	for _, network := range networks {
+		if !slices.Contains(unique, network) {
+			unique = append(unique, network)
+		}
+	}
+	return unique
+}
+
+func (m *Mandate) matches(info *pkg.BINInfo) bool {
+	if info == nil {
+		return false
+	}
+	if len(m.Countries) > 0 && !slices.ContainsFunc(m.Countries, func(country string) bool {
+		return strings.EqualFold(country, info.Country)
+	}) {
+		return false
+	}
+	if len(m.Funding) > 0 && !slices.Contains(m.Funding, info.Funding) {
+		return false
+	}
+	return !m.RegulatedOnly || info.Regulated
+}
+
+// cost returns the fee of the network for the amount, false without cost table.
+func (e *Engine) cost(network pkg.Schema, amount int64) (int64, bool) {
+	for _, tier := range e.cfg.Costs[network] {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			return tier.Fixed + amount*tier.BasisPoints/10000, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,225 @@
+//go:build unit
+
+package routing
+
+import (
+	"testing"
+
+	"card/pkg"
+	"card/pkg/card"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	networkPulse pkg.Schema = "Pulse"
+	networkStar  pkg.Schema = "Star"
+)
+
+// schemaLookup classifies every number as its schema, like a BIN database disagreeing with the built-in table.
+type schemaLookup pkg.Schema
+
+func (sl schemaLookup) Match(string) (pkg.Schema, bool, error) {
+	return pkg.Schema(sl), true, nil
+}
+
+func TestEngineRoute(t *testing.T) {
+	visa, err := card.NewCreditCard("4111 1111 1111 1111")
+	assert.NoError(t, err)
+	reclassified, err := card.NewCreditCard("4111 1111 1111 1111", card.WithLookup(schemaLookup(pkg.SchemaMasterCard)))
+	assert.NoError(t, err)
+
+	regulatedDebit := &pkg.BINInfo{Country: "US", Funding: pkg.FundingDebit, Networks: []pkg.Schema{networkPulse, networkStar}, Regulated: true}
+	exemptDebit := &pkg.BINInfo{Country: "US", Funding: pkg.FundingDebit, Networks: []pkg.Schema{networkPulse, networkStar}}
+	costs := map[pkg.Schema][]CostTier{
+		pkg.SchemaVisa: {{UpTo: 0, Fixed: 10, BasisPoints: 80}},
+		networkPulse:   {{UpTo: 1500, Fixed: 5, BasisPoints: 0}, {Fixed: 20, BasisPoints: 50}},
+		networkStar:    {{Fixed: 12, BasisPoints: 40}},
+	}
+	durbin := Mandate{Name: "durbin", Network: networkStar, Countries: []string{"us"}, Funding: []pkg.Funding{pkg.FundingDebit}, RegulatedOnly: true}
+
+	cases := []struct {
+		name           string
+		config         Config
+		request        Request
+		expectedRoutes []Route
+		expectedError  error
+	}{
+		{
+			"should-keep-candidate-order-without-rules",
+			Config{},
+			Request{Card: visa, BIN: exemptDebit, Amount: 1000},
+			[]Route{
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+				{networkPulse, 0, false, "no preference or cost, candidate order"},
+				{networkStar, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-follow-merchant-preference",
+			Config{Preference: []pkg.Schema{networkStar, pkg.SchemaMasterCard, networkPulse}},
+			Request{Card: visa, BIN: exemptDebit, Amount: 1000},
+			[]Route{
+				{networkStar, 0, false, "merchant preference #1"},
+				{networkPulse, 0, false, "merchant preference #3"},
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-order-by-cost-of-small-amount",
+			Config{Costs: costs},
+			Request{Card: visa, BIN: exemptDebit, Amount: 1000},
+			[]Route{
+				{networkPulse, 5, true, "cost 5 for amount 1000"},
+				{networkStar, 16, true, "cost 16 for amount 1000"},
+				{pkg.SchemaVisa, 18, true, "cost 18 for amount 1000"},
+			},
+			nil,
+		},
+		{
+			"should-order-by-cost-of-large-amount",
+			Config{Costs: costs},
+			Request{Card: visa, BIN: exemptDebit, Amount: 10000},
+			[]Route{
+				{networkStar, 52, true, "cost 52 for amount 10000"},
+				{networkPulse, 70, true, "cost 70 for amount 10000"},
+				{pkg.SchemaVisa, 90, true, "cost 90 for amount 10000"},
+			},
+			nil,
+		},
+		{
+			"should-put-uncosted-networks-last",
+			Config{Costs: map[pkg.Schema][]CostTier{networkStar: costs[networkStar]}},
+			Request{Card: visa, BIN: exemptDebit, Amount: 1000},
+			[]Route{
+				{networkStar, 16, true, "cost 16 for amount 1000"},
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+				{networkPulse, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-put-mandated-network-first",
+			Config{Mandates: []Mandate{durbin}, Preference: []pkg.Schema{pkg.SchemaVisa}, Costs: costs},
+			Request{Card: visa, BIN: regulatedDebit, Amount: 1000},
+			[]Route{
+				{networkStar, 16, true, `mandated by "durbin"`},
+				{pkg.SchemaVisa, 18, true, "merchant preference #1"},
+				{networkPulse, 5, true, "cost 5 for amount 1000"},
+			},
+			nil,
+		},
+		{
+			"should-skip-mandate-for-exempt-issuer",
+			Config{Mandates: []Mandate{durbin}, Preference: []pkg.Schema{pkg.SchemaVisa}},
+			Request{Card: visa, BIN: exemptDebit, Amount: 1000},
+			[]Route{
+				{pkg.SchemaVisa, 0, false, "merchant preference #1"},
+				{networkPulse, 0, false, "no preference or cost, candidate order"},
+				{networkStar, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-skip-mandate-without-bin-data",
+			Config{Mandates: []Mandate{durbin}},
+			Request{Card: visa, Amount: 1000},
+			[]Route{
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-skip-mandate-for-other-country",
+			Config{Mandates: []Mandate{durbin}},
+			Request{Card: visa, BIN: &pkg.BINInfo{Country: "CA", Funding: pkg.FundingDebit, Networks: []pkg.Schema{networkStar}, Regulated: true}},
+			[]Route{
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+				{networkStar, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-skip-mandate-for-network-not-on-card",
+			Config{Mandates: []Mandate{durbin}},
+			Request{Card: visa, BIN: &pkg.BINInfo{Country: "US", Funding: pkg.FundingDebit, Networks: []pkg.Schema{networkPulse}, Regulated: true}},
+			[]Route{
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+				{networkPulse, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-route-schema-of-card-lookup-only",
+			Config{},
+			// The built-in table classifies the number as Visa, which must not become a candidate.
+			Request{Card: reclassified, BIN: &pkg.BINInfo{Networks: []pkg.Schema{networkPulse}}},
+			[]Route{
+				{pkg.SchemaMasterCard, 0, false, "no preference or cost, candidate order"},
+				{networkPulse, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-use-explicit-candidates",
+			Config{},
+			Request{Card: visa, Candidates: []pkg.Schema{networkPulse, networkPulse, pkg.SchemaVisa}},
+			[]Route{
+				{networkPulse, 0, false, "no preference or cost, candidate order"},
+				{pkg.SchemaVisa, 0, false, "no preference or cost, candidate order"},
+			},
+			nil,
+		},
+		{
+			"should-fail-without-candidates",
+			Config{},
+			Request{Candidates: []pkg.Schema{}},
+			nil,
+			ErrNoNetwork,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			engine, err := NewEngine(c.config)
+			assert.NoError(t, err)
+
+			routes, err := engine.Route(c.request)
+			assert.ErrorIs(t, err, c.expectedError)
+			assert.Equal(t, c.expectedRoutes, routes)
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		config Config
+	}{
+		{
+			"should-reject-unbounded-tier-before-last",
+			Config{Costs: map[pkg.Schema][]CostTier{networkStar: {{Fixed: 1}, {UpTo: 100, Fixed: 2}}}},
+		},
+		{
+			"should-reject-descending-tiers",
+			Config{Costs: map[pkg.Schema][]CostTier{networkStar: {{UpTo: 100}, {UpTo: 50}}}},
+		},
+		{
+			"should-reject-negative-cost",
+			Config{Costs: map[pkg.Schema][]CostTier{networkStar: {{BasisPoints: -1}}}},
+		},
+		{
+			"should-reject-mandate-without-network",
+			Config{Mandates: []Mandate{{Name: "durbin"}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewEngine(c.config)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"card/pkg"
+	"card/pkg/card"
+	"card/pkg/i18n"
+	"card/pkg/policy"
+)
+
+// maxBodyBytes bounds request bodies, a validation request is a few dozen bytes.
+const maxBodyBytes = 4 << 10
+
+type validateRequest struct {
+	Number string `json:"number"`
+	Policy string `json:"policy,omitempty"`
+}
+
+type validateResponse struct {
+	Valid    bool             `json:"valid"`
+	Schema   pkg.Schema       `json:"schema"`
+	Decision *policy.Decision `json:"decision,omitempty"`
+}
+
+type errorResponse struct {
+	Error   string `json:"error"`
+	Code    string `json:"code,omitempty"`    // Message key of card validation errors.
+	Message string `json:"message,omitempty"` // Localized for the end user by Accept-Language.
+}
+
+type handler struct {
+	policies  policy.Set
+	validator *card.Validator
+	catalog   *i18n.Catalog
+}
+
+// NewHandler serves card validation over HTTP:
+//
+//	POST /validate {"number": "4012 8888 8888 1881", "policy": "merchant-a"}
+//
+// The policy is optional, when given the response carries its decision, including issuer country and
+// funding rules when the validator's lookup is a utils.BINLookup.
+// The card number is never echoed back. The options configure the validator, e.g. its audit sink;
+// audit records name the client address of the request as caller.
+func NewHandler(policies policy.Set, opts ...card.Option) http.Handler {
+	h := &handler{policies: policies, validator: card.NewValidator(opts...), catalog: i18n.DefaultCatalog()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /validate", h.validate)
+	return mux
+}
+
+func (h *handler) validate(w http.ResponseWriter, r *http.Request) {
+	var req validateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "request body too large"})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed request body"})
+		return
+	}
+
+	var p *policy.Policy
+	if req.Policy != "" {
+		var err error
+		if p, err = h.policies.Get(req.Policy); err != nil {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	validator := h.validator.ForCaller(caller(r))
+	creditCard, err := validator.NewCreditCard(req.Number)
+	if errors.Is(err, card.ErrAudit) {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "validation could not be audited"})
+		return
+	} else if err != nil {
+		locale := h.catalog.Match(r.Header.Get("Accept-Language"))
+		msg := i18n.FromError(err)
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{err.Error(), msg.Key, h.catalog.Format(locale, msg)})
+		return
+	}
+
+	resp := validateResponse{Valid: creditCard.Valid(), Schema: creditCard.Schema()}
+	if p != nil {
+		info, err := validator.BINInfo(creditCard)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "BIN data unavailable"})
+			return
+		}
+		decision := p.Evaluate(creditCard, info)
+		resp.Decision = &decision
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// caller identifies the client of the request in audit records by its address.
+func caller(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,276 @@
+//go:build unit
+
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"card/pkg"
+	"card/pkg/card"
+	"card/pkg/policy"
+	"card/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerValidate(t *testing.T) {
+	policies := policy.Set{
+		"visa-only": {
+			Name:    "visa-only",
+			Default: policy.ActionDeny,
+			Rules:   []policy.Rule{{Name: "visa", Action: policy.ActionAllow, Schemas: []pkg.Schema{pkg.SchemaVisa}}},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			"should-validate-without-policy",
+			`{"number": "4012 8888 8888 1881"}`,
+			http.StatusOK,
+			`{"valid": true, "schema": "Visa"}`,
+		},
+		{
+			"should-apply-named-policy",
+			`{"number": "5105105105105100", "policy": "visa-only"}`,
+			http.StatusOK,
+			`{"valid": true, "schema": "MasterCard", "decision": {"accepted": false, "rule": "default"}}`,
+		},
+		{
+			"should-return-not-found-for-unknown-policy",
+			`{"number": "5105105105105100", "policy": "none"}`,
+			http.StatusNotFound,
+			`{"error": "unknown policy \"none\""}`,
+		},
+		{
+			"should-return-unprocessable-for-invalid-number",
+			`{"number": ""}`,
+			http.StatusUnprocessableEntity,
+			`{"error": "invalid card number: empty", "code": "card.empty", "message": "Please enter your card number."}`,
+		},
+		{
+			"should-return-bad-request-for-malformed-body",
+			`{"number": `,
+			http.StatusBadRequest,
+			`{"error": "malformed request body"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(c.body))
+			NewHandler(policies).ServeHTTP(rec, req)
+
+			assert.Equal(t, c.expectedStatus, rec.Code)
+			assert.JSONEq(t, c.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlerLocalizesErrors(t *testing.T) {
+	cases := []struct {
+		name             string
+		acceptLanguage   string
+		body             string
+		expectedLanguage string
+		expectedBody     string
+	}{
+		{
+			"should-localize-non-digit-position-in-german",
+			"de-CH, en;q=0.5",
+			`{"number": "4012-8888"}`,
+			"de",
+			`{"error": "invalid card number: contains non-digit characters", "code": "card.non_digit",
+			  "message": "Die Kartennummer darf nur Ziffern enthalten, bitte prüfen Sie das Zeichen an Position 5."}`,
+		},
+		{
+			"should-localize-non-digit-position-in-raw-input",
+			"de",
+			`{"number": "4111 11x1 1111 1111"}`,
+			"de",
+			`{"error": "invalid card number: contains non-digit characters", "code": "card.non_digit",
+			  "message": "Die Kartennummer darf nur Ziffern enthalten, bitte prüfen Sie das Zeichen an Position 8."}`,
+		},
+		{
+			"should-localize-length-in-russian",
+			"ru",
+			`{"number": "11111111111111111111"}`,
+			"ru",
+			`{"error": "invalid card number: too long", "code": "card.too_long",
+			  "message": "Номер карты может содержать не более 19 цифр."}`,
+		},
+		{
+			"should-fall-back-to-english",
+			"pt-BR",
+			`{"number": ""}`,
+			"en",
+			`{"error": "invalid card number: empty", "code": "card.empty", "message": "Please enter your card number."}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(c.body))
+			req.Header.Set("Accept-Language", c.acceptLanguage)
+			NewHandler(policy.Set{}).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Equal(t, c.expectedLanguage, rec.Header().Get("Content-Language"))
+			assert.JSONEq(t, c.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Audit(card.AuditRecord) error {
+	return errors.New("disk full")
+}
+
+func TestHandlerAuditFailure(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"number": "4012888888881881"}`))
+	NewHandler(policy.Set{}, card.WithAuditSink(failingSink{}, nil)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error": "validation could not be audited"}`, rec.Body.String())
+}
+
+type recordingSink struct {
+	records []card.AuditRecord
+}
+
+func (rs *recordingSink) Audit(record card.AuditRecord) error {
+	rs.records = append(rs.records, record)
+	return nil
+}
+
+func TestHandlerAuditsClientAddress(t *testing.T) {
+	sink := &recordingSink{}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"number": "4012888888881881"}`))
+	req.RemoteAddr = "198.51.100.7:51234"
+	NewHandler(policy.Set{}, card.WithAuditSink(sink, nil)).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, sink.records, 1)
+	assert.Equal(t, "198.51.100.7", sink.records[0].Caller)
+}
+
+func TestHandlerRejectsLargeBody(t *testing.T) {
+	body := `{"number": "4012888888881881", "policy": "` + strings.Repeat("x", maxBodyBytes) + `"}`
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(body))
+	NewHandler(policy.Set{}).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.JSONEq(t, `{"error": "request body too large"}`, rec.Body.String())
+}
+
+type binLookup struct {
+	info *pkg.BINInfo
+	err  error
+}
+
+func (bl *binLookup) Match(string) (pkg.Schema, bool, error) {
+	return pkg.SchemaVisa, true, nil
+}
+
+func (bl *binLookup) BINInfo(string) (*pkg.BINInfo, error) {
+	return bl.info, bl.err
+}
+
+func TestHandlerAppliesBINData(t *testing.T) {
+	policies := policy.Set{
+		"no-prepaid": {
+			Name:    "no-prepaid",
+			Default: policy.ActionAllow,
+			Rules:   []policy.Rule{{Name: "prepaid", Action: policy.ActionDeny, Funding: []pkg.Funding{pkg.FundingPrepaid}}},
+		},
+	}
+
+	cases := []struct {
+		name           string
+		lookup         *binLookup
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			"should-deny-by-funding",
+			&binLookup{info: &pkg.BINInfo{Country: "DE", Funding: pkg.FundingPrepaid}},
+			http.StatusOK,
+			`{"valid": true, "schema": "Visa", "decision": {"accepted": false, "rule": "prepaid"}}`,
+		},
+		{
+			"should-fall-back-to-default-without-bin-data",
+			&binLookup{},
+			http.StatusOK,
+			`{"valid": true, "schema": "Visa", "decision": {"accepted": true, "rule": "default"}}`,
+		},
+		{
+			"should-fail-when-bin-data-is-unavailable",
+			&binLookup{err: errors.New("connection refused")},
+			http.StatusInternalServerError,
+			`{"error": "BIN data unavailable"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"number": "4012888888881881", "policy": "no-prepaid"}`))
+			NewHandler(policies, card.WithLookup(c.lookup)).ServeHTTP(rec, req)
+
+			assert.Equal(t, c.expectedStatus, rec.Code)
+			assert.JSONEq(t, c.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlerAppliesCountryRuleFromBINFile(t *testing.T) {
+	policies, err := policy.Load(strings.NewReader(`{"policies": {"domestic": {"default": "deny",
+		"rules": [{"name": "german-issuers", "action": "allow", "countries": ["DE"]}]}}}`))
+	assert.NoError(t, err)
+	bins, _, err := utils.LoadBINTable(strings.NewReader(`{"bins": [{"range": "401288", "country": "DE", "funding": "debit"},
+		{"range": "411111", "country": "US", "funding": "credit"}]}`), nil)
+	assert.NoError(t, err)
+	handler := NewHandler(policies, card.WithLookup(bins))
+
+	cases := []struct {
+		name         string
+		number       string
+		expectedBody string
+	}{
+		{
+			"should-accept-issuer-of-allowed-country",
+			"4012888888881881",
+			`{"valid": true, "schema": "Visa", "decision": {"accepted": true, "rule": "german-issuers"}}`,
+		},
+		{
+			"should-decline-issuer-of-other-country",
+			"4111111111111111",
+			`{"valid": true, "schema": "Visa", "decision": {"accepted": false, "rule": "default"}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"number": "`+c.number+`", "policy": "domestic"}`))
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, c.expectedBody, rec.Body.String())
+		})
+	}
+}
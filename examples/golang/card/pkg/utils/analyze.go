@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"card/pkg"
+)
+
+// maxPrefixDigits is the widest prefix range Analyze can match; the leading digits are kept in an int.
+const maxPrefixDigits = 8
+
+// Analysis is the result of Analyze.
+type Analysis struct {
+	Length int        // Number of digits after normalization.
+	Valid  bool       // Whether the checksum is valid.
+	Schema pkg.Schema // SchemaUnknown when no scheme matched.
+}
+
+type compiledRange struct {
+	start, end int
+	width      int
+	period     period // Already narrowed to the scheme period.
+}
+
+type compiledScheme struct {
+	name     pkg.Schema
+	prefixes []compiledRange
+	lengths  []int
+}
+
+// defaultCompiledSchemes is built once so Analyze does not allocate per call.
+var defaultCompiledSchemes = sync.OnceValue(func() []compiledScheme {
+	schemes, err := compileSchemes(defaultCardSchemes())
+	if err != nil {
+		panic(err)
+	}
+	return schemes
+})
+
+// Analyze normalizes, validates and classifies a card number in a single pass without heap allocations,
+// only rejecting a number allocates its error. Schemes are the ones effective now, see AnalyzeAt.
+// It accepts the raw input and gets the same result as NormalizeCardNumber followed by CardValid and
+// CardSchema: spaces and surrounding Unicode whitespace are ignored, errors are the *ValidationError of
+// CardValid with positions in the normalized number.
+func Analyze[T ~string | ~[]byte](cardNumber T) (Analysis, error) {
+	return AnalyzeAt(cardNumber, time.Now())
+}
+
+// AnalyzeAt is Analyze with the schemes effective at the given time.
+func AnalyzeAt[T ~string | ~[]byte](cardNumber T, at time.Time) (Analysis, error) {
+	return analyze(defaultCompiledSchemes(), cardNumber, at)
+}
+
+func analyze[T ~string | ~[]byte](schemes []compiledScheme, cardNumber T, at time.Time) (Analysis, error) {
+	var (
+		length     int // Normalized length in bytes, non-digits included.
+		nonDigit   int // 1-based position of the first non-digit in the normalized number.
+		prefix     int // Leading digits, up to maxPrefixDigits.
+		evenDouble int // Luhn sum doubling digits at even indexes.
+		oddDouble  int // Luhn sum doubling digits at odd indexes.
+	)
+
+	// Trimming the whitespace around the spaceless number is trimming it around the raw input.
+	first, last := 0, len(cardNumber)
+	for first < last {
+		size := leadingSpace(cardNumber[first:last])
+		if size == 0 {
+			break
+		}
+		first += size
+	}
+	for first < last {
+		size := trailingSpace(cardNumber[first:last])
+		if size == 0 {
+			break
+		}
+		last -= size
+	}
+
+	for i := first; i < last; i++ {
+		ch := cardNumber[i]
+		if ch == ' ' {
+			continue
+		}
+		if ch < '0' || ch > '9' {
+			if nonDigit == 0 {
+				nonDigit = length + 1
+			}
+			length++
+			continue
+		}
+
+		digit := int(ch - '0')
+		doubled := digit * 2
+		if doubled > 9 {
+			doubled -= 9
+		}
+		if length%2 == 0 {
+			evenDouble += doubled
+			oddDouble += digit
+		} else {
+			evenDouble += digit
+			oddDouble += doubled
+		}
+		if length < maxPrefixDigits {
+			prefix = prefix*10 + digit
+		}
+		length++
+	}
+
+	switch {
+	case length == 0:
+		return Analysis{Schema: pkg.SchemaUnknown}, &ValidationError{Kind: ErrEmpty}
+	case length > MaxCardLength:
+		return Analysis{Schema: pkg.SchemaUnknown}, &ValidationError{Kind: ErrTooLong, Length: length}
+	case nonDigit > 0:
+		return Analysis{Schema: pkg.SchemaUnknown}, &ValidationError{Kind: ErrNonDigit, Length: length, Position: nonDigit}
+	}
+
+	// The rightmost digit is never doubled, so the doubled indexes share the parity of the length.
+	checksum := oddDouble
+	if length%2 == 0 {
+		checksum = evenDouble
+	}
+
+	return Analysis{
+		Length: length,
+		Valid:  checksum%10 == 0,
+		Schema: matchCompiled(schemes, prefix, min(length, maxPrefixDigits), length, at),
+	}, nil
+}
+
+// leadingSpace returns the size of the whitespace character s starts with, as strings.TrimSpace sees it,
+// 0 when there is none. Decoding from a fixed buffer keeps []byte input from being copied to a string.
+func leadingSpace[T ~string | ~[]byte](s T) int {
+	if s[0] < utf8.RuneSelf {
+		return asciiSpace(s[0])
+	}
+	var buf [utf8.UTFMax]byte
+	r, size := utf8.DecodeRune(buf[:copy(buf[:], s)])
+	if !unicode.IsSpace(r) {
+		return 0
+	}
+	return size
+}
+
+// trailingSpace is leadingSpace for the whitespace character s ends with.
+func trailingSpace[T ~string | ~[]byte](s T) int {
+	if s[len(s)-1] < utf8.RuneSelf {
+		return asciiSpace(s[len(s)-1])
+	}
+	var buf [utf8.UTFMax]byte
+	r, size := utf8.DecodeLastRune(buf[:copy(buf[:], s[max(0, len(s)-utf8.UTFMax):])])
+	if !unicode.IsSpace(r) {
+		return 0
+	}
+	return size
+}
+
+func asciiSpace(ch byte) int {
+	switch ch {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return 1
+	}
+	return 0
+}
+
+func matchCompiled(schemes []compiledScheme, prefix, prefixDigits, length int, at time.Time) pkg.Schema {
+	for _, scheme := range schemes {
+		if !slices.Contains(scheme.lengths, length) {
+			continue
+		}
+		for _, r := range scheme.prefixes {
+			if r.width > prefixDigits || !r.period.contains(at) {
+				continue
+			}
+			leading := prefix / pow10(prefixDigits-r.width)
+			if leading >= r.start && leading <= r.end {
+				return scheme.name
+			}
+		}
+	}
+	return pkg.SchemaUnknown
+}
+
+func compileSchemes(schemes []cardScheme) ([]compiledScheme, error) {
+	compiled := make([]compiledScheme, 0, len(schemes))
+	for _, scheme := range schemes {
+		cs := compiledScheme{name: scheme.name, lengths: scheme.lengths}
+		for _, prefixRange := range scheme.prefixes {
+			r, err := compileRange(prefixRange)
+			if err != nil {
+				return nil, fmt.Errorf("scheme %s: prefix %q: %w", scheme.name, prefixRange, err)
+			}
+			r.period = scheme.prefixPeriod(prefixRange)
+			cs.prefixes = append(cs.prefixes, r)
+		}
+		compiled = append(compiled, cs)
+	}
+	return compiled, nil
+}
+
+func compileRange(prefixRange string) (compiledRange, error) {
+	startText, endText, isRange := strings.Cut(prefixRange, "-")
+	if !isRange {
+		endText = startText
+	}
+
+	switch {
+	case startText == "" || endText == "":
+		return compiledRange{}, errors.New("missing range bound")
+	case !IsDigits(startText) || !IsDigits(endText):
+		return compiledRange{}, errors.New("non-digit characters")
+	case len(startText) > maxPrefixDigits || len(endText) > maxPrefixDigits:
+		return compiledRange{}, fmt.Errorf("longer than %d digits", maxPrefixDigits)
+	}
+
+	// Both bounds are short digit strings, Atoi cannot fail.
+	start, _ := strconv.Atoi(startText)
+	end, _ := strconv.Atoi(endText)
+	return compiledRange{start: start, end: end, width: len(startText)}, nil
+}
+
+// IsDigits reports whether s consists of ASCII digits only, the empty string included.
+func IsDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) int {
+	result := 1
+	for ; n > 0; n-- {
+		result *= 10
+	}
+	return result
+}
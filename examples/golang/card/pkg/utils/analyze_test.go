@@ -0,0 +1,191 @@
+//go:build unit
+
+package utils
+
+import (
+	"testing"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	cases := []struct {
+		name           string
+		cardNumber     string
+		expectedResult Analysis
+		expectedError  *ValidationError
+	}{
+		{
+			"should-analyze-american-express",
+			"  3782 8224 6310 005  ",
+			Analysis{Length: 15, Valid: true, Schema: pkg.SchemaAmericanExpress},
+			nil,
+		},
+		{
+			"should-analyze-jcb",
+			"3530111333300000",
+			Analysis{Length: 16, Valid: true, Schema: pkg.SchemaJCB},
+			nil,
+		},
+		{
+			"should-analyze-invalid-maestro",
+			"6759649826438454",
+			Analysis{Length: 16, Valid: false, Schema: pkg.SchemaMaestro},
+			nil,
+		},
+		{
+			"should-analyze-odd-length-visa",
+			"4222222222222\n",
+			Analysis{Length: 13, Valid: true, Schema: pkg.SchemaVisa},
+			nil,
+		},
+		{
+			"should-trim-unicode-whitespace",
+			"\u00a05105 1051 0510 5100\u3000",
+			Analysis{Length: 16, Valid: true, Schema: pkg.SchemaMasterCard},
+			nil,
+		},
+		{
+			"should-analyze-master-card-2-series",
+			"2223003122003222",
+			Analysis{Length: 16, Valid: true, Schema: pkg.SchemaMasterCard},
+			nil,
+		},
+		{
+			"should-analyze-unknown-schema",
+			"9105105105105100",
+			Analysis{Length: 16, Valid: false, Schema: pkg.SchemaUnknown},
+			nil,
+		},
+		{
+			"should-analyze-single-digit",
+			"0",
+			Analysis{Length: 1, Valid: true, Schema: pkg.SchemaUnknown},
+			nil,
+		},
+		{
+			"should-return-error-empty",
+			" \u2003 ",
+			Analysis{Schema: pkg.SchemaUnknown},
+			&ValidationError{Kind: ErrEmpty},
+		},
+		{
+			"should-return-error-too-long",
+			"11111111111111111111",
+			Analysis{Schema: pkg.SchemaUnknown},
+			&ValidationError{Kind: ErrTooLong, Length: 20},
+		},
+		{
+			"should-return-error-contains-not-digits",
+			"5105105105105100.",
+			Analysis{Schema: pkg.SchemaUnknown},
+			&ValidationError{Kind: ErrNonDigit, Length: 17, Position: 17},
+		},
+		{
+			"should-return-error-for-inner-tab",
+			"5105\t105105105100",
+			Analysis{Schema: pkg.SchemaUnknown},
+			&ValidationError{Kind: ErrNonDigit, Length: 17, Position: 5},
+		},
+		{
+			"should-return-error-for-inner-no-break-space",
+			" 5105 105\u00a0105105100",
+			Analysis{Schema: pkg.SchemaUnknown},
+			&ValidationError{Kind: ErrNonDigit, Length: 18, Position: 8},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			analysis, err := Analyze(c.cardNumber)
+			assertValidationError(t, c.expectedError, err)
+			assert.Equal(t, c.expectedResult, analysis)
+
+			analysis, err = Analyze([]byte(c.cardNumber))
+			assertValidationError(t, c.expectedError, err)
+			assert.Equal(t, c.expectedResult, analysis)
+		})
+	}
+}
+
+func assertValidationError(t *testing.T, expected *ValidationError, err error) {
+	t.Helper()
+	if expected == nil {
+		assert.NoError(t, err)
+		return
+	}
+	assert.ErrorIs(t, err, expected.Kind)
+	assert.Equal(t, expected, err)
+}
+
+func TestAnalyzeMatchesSeparateCalls(t *testing.T) {
+	for _, cardNumber := range []string{
+		"378282246310005", "371449635398431", "3530111333300000", "3589000000000003", "6759649826438453",
+		"5000000000000", "4012888888881881", "4111111111111", "4000000000000000006", "5105105105105100",
+		"2720999999999996", "2221000000000009", "1234567812345670", "3400000000000000",
+		"\u00a0 5105105105105100 \u00a0", "\u20285105105105105100\u0085",
+	} {
+		normalized := NormalizeCardNumber(cardNumber)
+		valid, err := CardValid(normalized)
+		assert.NoError(t, err)
+		schema, err := CardSchema(normalized)
+		assert.NoError(t, err)
+
+		analysis, err := Analyze(cardNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, Analysis{Length: len(normalized), Valid: valid, Schema: schema}, analysis, cardNumber)
+	}
+}
+
+func TestAnalyzeMatchesSeparateCallsOnErrors(t *testing.T) {
+	for _, cardNumber := range []string{
+		"5105\t105105105100", "5105\t\t\t\t105105105100", "51051051 05105100.", " \n\t ",
+		"\u00a05105\u00a0105105105100", "4111\u00a01111\u00a01111\u00a01111", "\xa05105105105105100",
+	} {
+		_, expected := CardValid(NormalizeCardNumber(cardNumber))
+		_, err := Analyze(cardNumber)
+		assert.Equal(t, expected, err, cardNumber)
+	}
+}
+
+func TestAnalyzeDoesNotAllocate(t *testing.T) {
+	cardNumber := "5237 2516 2477 8133"
+	cardBytes := []byte(cardNumber)
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _ = Analyze(cardNumber)
+	}))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _ = Analyze(cardBytes)
+	}))
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_, _ = Analyze([]byte("\u00a05105105105105100\u00a0"))
+	}))
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Analyze("5237 2516 2477 8133")
+	}
+}
+
+func BenchmarkAnalyzeBytes(b *testing.B) {
+	cardNumber := []byte("5237 2516 2477 8133")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Analyze(cardNumber)
+	}
+}
+
+// BenchmarkSeparateCalls measures the path NewCreditCard takes.
+func BenchmarkSeparateCalls(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		normalized := NormalizeCardNumber("5237 2516 2477 8133")
+		_, _ = CardValid(normalized)
+		_, _ = CardSchema(normalized)
+	}
+}
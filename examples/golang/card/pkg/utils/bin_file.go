@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"card/pkg"
+)
+
+// binFile is the JSON representation of issuer data per BIN range:
+//
+//	{"version": "2024-06", "bins": [{"range": "401288", "country": "US", "funding": "debit",
+//	 "networks": ["Pulse"], "regulated": true}]}
+//
+// Ranges are "NNN" or "NNN-MMM" like the BIN ranges of policies. When ranges overlap the longest one
+// applies, among equally long ones the first in file order.
+type binFile struct {
+	Version string        `json:"version"`
+	BINs    []binFileItem `json:"bins"`
+}
+
+type binFileItem struct {
+	Range     string       `json:"range"`
+	Country   string       `json:"country,omitempty"` // ISO 3166-1 alpha-2.
+	Funding   pkg.Funding  `json:"funding,omitempty"`
+	Networks  []pkg.Schema `json:"networks,omitempty"`
+	Regulated bool         `json:"regulated,omitempty"`
+}
+
+type binEntry struct {
+	binRange string
+	info     pkg.BINInfo
+}
+
+// binTable classifies with a scheme lookup and adds the issuer data of a BIN file.
+type binTable struct {
+	schemes CardLookup
+	bins    []binEntry // Longest ranges first.
+}
+
+// ValidBINRange accepts "NNN" and "NNN-MMM" with bounds of equal width.
+func ValidBINRange(binRange string) bool {
+	start, end, isRange := strings.Cut(binRange, "-")
+	if !isRange {
+		end = start
+	}
+	return start != "" && len(start) == len(end) && IsDigits(start) && IsDigits(end) && start <= end
+}
+
+// LoadBINFile reads a BIN data file, see LoadBINTable.
+func LoadBINFile(path string, schemes CardLookup) (BINLookup, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return LoadBINTable(bytes.NewReader(data), schemes)
+}
+
+// LoadBINTable parses and fully validates JSON BIN data. The returned lookup classifies with schemes,
+// the built-in table when nil, and answers BINInfo from the data.
+// It returns the data version, a content hash when the file does not declare one.
+func LoadBINTable(r io.Reader, schemes CardLookup) (BINLookup, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file binFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, "", fmt.Errorf("invalid BIN data: %w", err)
+	}
+	if len(file.BINs) == 0 {
+		return nil, "", errors.New("invalid BIN data: no bins")
+	}
+
+	if schemes == nil {
+		schemes = DefaultLookup()
+	}
+	table := &binTable{schemes: schemes, bins: make([]binEntry, 0, len(file.BINs))}
+	for _, item := range file.BINs {
+		if err := item.validate(); err != nil {
+			return nil, "", fmt.Errorf("invalid BIN data: range %q: %w", item.Range, err)
+		}
+		table.bins = append(table.bins, binEntry{
+			binRange: item.Range,
+			info: pkg.BINInfo{
+				Country:   strings.ToUpper(item.Country),
+				Funding:   item.Funding,
+				Networks:  item.Networks,
+				Regulated: item.Regulated,
+			},
+		})
+	}
+	slices.SortStableFunc(table.bins, func(a, b binEntry) int {
+		return binRangeWidth(b.binRange) - binRangeWidth(a.binRange)
+	})
+
+	version := file.Version
+	if version == "" {
+		sum := sha256.Sum256(data)
+		version = hex.EncodeToString(sum[:6])
+	}
+	return table, version, nil
+}
+
+func (item binFileItem) validate() error {
+	if !ValidBINRange(item.Range) {
+		return errors.New("malformed range")
+	}
+	if item.Country != "" && !isCountryCode(item.Country) {
+		return fmt.Errorf("country %q is not an ISO 3166-1 alpha-2 code", item.Country)
+	}
+	switch item.Funding {
+	case "", pkg.FundingCredit, pkg.FundingDebit, pkg.FundingPrepaid:
+	default:
+		return fmt.Errorf("unknown funding %q", item.Funding)
+	}
+	return nil
+}
+
+// isCountryCode accepts two ASCII letters in any case.
+func isCountryCode(country string) bool {
+	if len(country) != 2 {
+		return false
+	}
+	for i := 0; i < len(country); i++ {
+		if ch := country[i] | 0x20; ch < 'a' || ch > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func binRangeWidth(binRange string) int {
+	start, _, _ := strings.Cut(binRange, "-")
+	return len(start)
+}
+
+func (bt *binTable) Match(cardNumber string) (pkg.Schema, bool, error) {
+	return bt.schemes.Match(cardNumber)
+}
+
+// MatchAt keeps the effective dates of the scheme lookup.
+func (bt *binTable) MatchAt(cardNumber string, at time.Time) (pkg.Schema, bool, error) {
+	if timed, ok := bt.schemes.(TimedLookup); ok {
+		return timed.MatchAt(cardNumber, at)
+	}
+	return bt.schemes.Match(cardNumber)
+}
+
+// BINInfo returns the data of the longest range the number falls in, nil when there is none.
+func (bt *binTable) BINInfo(cardNumber string) (*pkg.BINInfo, error) {
+	for _, entry := range bt.bins {
+		ok, err := matchPrefix(cardNumber, entry.binRange)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			info := entry.info
+			info.Networks = slices.Clone(info.Networks)
+			return &info, nil
+		}
+	}
+	return nil, nil
+}
+
+var (
+	_ BINLookup   = &binTable{}
+	_ TimedLookup = &binTable{}
+)
@@ -0,0 +1,109 @@
+//go:build unit
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidBINRange(t *testing.T) {
+	cases := []struct {
+		name           string
+		binRange       string
+		expectedResult bool
+	}{
+		{"should-accept-prefix", "4", true},
+		{"should-accept-range", "510000-559999", true},
+		{"should-reject-empty", "", false},
+		{"should-reject-open-range", "3528-", false},
+		{"should-reject-reversed-range", "9-1", false},
+		{"should-reject-mismatched-width", "51-5599", false},
+		{"should-reject-non-digits", "4x", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expectedResult, ValidBINRange(c.binRange))
+		})
+	}
+}
+
+func TestLoadBINTable(t *testing.T) {
+	lookup, version, err := LoadBINTable(strings.NewReader(`{
+		"version": "2024-06",
+		"bins": [
+			{"range": "4", "country": "us", "funding": "credit"},
+			{"range": "401288", "country": "DE", "funding": "debit", "networks": ["girocard"]},
+			{"range": "510000-519999", "funding": "prepaid"}
+		]
+	}`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06", version)
+
+	cases := []struct {
+		name         string
+		cardNumber   string
+		expectedInfo *pkg.BINInfo
+	}{
+		{
+			"should-use-longest-range",
+			"4012888888881881",
+			&pkg.BINInfo{Country: "DE", Funding: pkg.FundingDebit, Networks: []pkg.Schema{"girocard"}},
+		},
+		{
+			"should-fall-back-to-shorter-range",
+			"4111111111111111",
+			&pkg.BINInfo{Country: "US", Funding: pkg.FundingCredit},
+		},
+		{
+			"should-match-range-bounds",
+			"5105105105105100",
+			&pkg.BINInfo{Funding: pkg.FundingPrepaid},
+		},
+		{
+			"should-return-nil-without-data",
+			"378282246310005",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			info, err := lookup.BINInfo(c.cardNumber)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedInfo, info)
+		})
+	}
+
+	schema, matched, err := lookup.Match("4012888888881881")
+	assert.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, pkg.SchemaVisa, schema)
+}
+
+func TestLoadBINTableErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"should-reject-malformed-json", `{"bins": [`},
+		{"should-reject-unknown-fields", `{"bins": [{"range": "4", "issuer": "x"}]}`},
+		{"should-reject-empty-data", `{"bins": []}`},
+		{"should-reject-malformed-range", `{"bins": [{"range": "4x"}]}`},
+		{"should-reject-uneven-range", `{"bins": [{"range": "4-59"}]}`},
+		{"should-reject-invalid-country", `{"bins": [{"range": "4", "country": "USA"}]}`},
+		{"should-reject-unknown-funding", `{"bins": [{"range": "4", "funding": "charge"}]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := LoadBINTable(strings.NewReader(c.data), nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -3,11 +3,22 @@ package utils
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"card/pkg"
+	"card/pkg/checkdigit"
 )
 
-const maxCardLength = 19
+// MaxCardLength is the longest card number accepted, as ISO/IEC 7812 allows.
+const MaxCardLength = 19
+
+var luhn = checkdigit.NewLuhn()
+
+var (
+	ErrEmpty    = errors.New("invalid card number: empty")
+	ErrTooLong  = errors.New("invalid card number: too long")
+	ErrNonDigit = errors.New("invalid card number: contains non-digit characters")
+)
 
 // NormalizeCardNumber removes spaces and trims the input string
 func NormalizeCardNumber(cardNumber string) string {
@@ -23,12 +34,11 @@ func CardValid(cardNumber string) (bool, error) {
 		return false, err
 	}
 
-	digits, err := convertToDigits(cardNumber)
-	if err != nil {
-		return false, err
+	valid, err := luhn.Verify(cardNumber)
+	if errors.Is(err, checkdigit.ErrInvalidCharacter) {
+		return false, nonDigitError(cardNumber)
 	}
-
-	return validChecksum(digits), nil
+	return valid, err
 }
 
 // CardSchema determines the schema of a card based on its normalized card number.
@@ -37,11 +47,51 @@ func CardValid(cardNumber string) (bool, error) {
 // (such as spaces or dashes) must be removed before calling this function.
 // Input example: 378282246310005
 func CardSchema(cardNumber string) (pkg.Schema, error) {
+	return CardSchemaAt(cardNumber, time.Now())
+}
+
+// CardSchemaAt is CardSchema with the ranges effective at the given time,
+// e.g. to reprocess historical transactions.
+func CardSchemaAt(cardNumber string, at time.Time) (pkg.Schema, error) {
+	return LookupCardSchemaAt(newLookupTable(), cardNumber, at)
+}
+
+// CardSchemes returns all schemes matching a normalized card number, the first one being
+// the one CardSchema returns. It is empty when no scheme matches.
+func CardSchemes(cardNumber string) ([]pkg.Schema, error) {
+	return CardSchemesAt(cardNumber, time.Now())
+}
+
+// CardSchemesAt is CardSchemes with the ranges effective at the given time.
+func CardSchemesAt(cardNumber string, at time.Time) ([]pkg.Schema, error) {
+	if err := validateCardNumberLength(cardNumber); err != nil {
+		return nil, err
+	}
+	return newLookupTable().(*lookupTable).MatchAll(cardNumber, at)
+}
+
+// LookupCardSchema is CardSchema with a caller supplied lookup, such as a BIN database.
+func LookupCardSchema(lookup CardLookup, cardNumber string) (pkg.Schema, error) {
+	return LookupCardSchemaAt(lookup, cardNumber, time.Now())
+}
+
+// LookupCardSchemaAt is CardSchemaAt with a caller supplied lookup.
+// Lookups not implementing TimedLookup are not versioned and classify the same at any time.
+func LookupCardSchemaAt(lookup CardLookup, cardNumber string, at time.Time) (pkg.Schema, error) {
 	if err := validateCardNumberLength(cardNumber); err != nil {
 		return pkg.SchemaUnknown, err
 	}
 
-	schema, matched, err := newLookupTable().Match(cardNumber)
+	var (
+		schema  pkg.Schema
+		matched bool
+		err     error
+	)
+	if timed, ok := lookup.(TimedLookup); ok {
+		schema, matched, err = timed.MatchAt(cardNumber, at)
+	} else {
+		schema, matched, err = lookup.Match(cardNumber)
+	}
 	if err != nil {
 		return pkg.SchemaUnknown, err
 	} else if !matched {
@@ -54,43 +104,9 @@ func CardSchema(cardNumber string) (pkg.Schema, error) {
 func validateCardNumberLength(cardNumber string) error {
 	numLen := len(cardNumber)
 	if numLen == 0 {
-		return errors.New("invalid card number: empty")
-	} else if numLen > maxCardLength {
-		return errors.New("invalid card number: too long")
+		return &ValidationError{Kind: ErrEmpty}
+	} else if numLen > MaxCardLength {
+		return &ValidationError{Kind: ErrTooLong, Length: numLen}
 	}
 	return nil
 }
-
-// convertToDigits converts a card number string into a slice of integers
-func convertToDigits(cardNumber string) ([]int, error) {
-	numbers := make([]int, 0, len(cardNumber))
-	for _, digit := range cardNumber {
-		if digit < '0' || digit > '9' {
-			return nil, errors.New("invalid card number: contains non-digit characters")
-		}
-		numbers = append(numbers, int(digit-'0'))
-	}
-	return numbers, nil
-}
-
-// validChecksum validates a sequence of digits.
-// It processes the digits in reverse order, doubling every second digit (from the right).
-// If a doubled digit exceeds 9, its digits are summed (e.g., 18 becomes 1 + 8 = 9).
-// Returns true if the checksum is valid (divisible by 10).
-func validChecksum(digits []int) bool {
-	checksum := 0
-	second := false
-	for p := len(digits) - 1; p >= 0; p-- {
-		number := digits[p]
-
-		if second {
-			number = digits[p] * 2
-		}
-
-		checksum += number / 10
-		checksum += number % 10
-
-		second = !second
-	}
-	return (checksum % 10) == 0
-}
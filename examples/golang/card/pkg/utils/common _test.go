@@ -5,6 +5,7 @@ package utils
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"card/pkg"
 
@@ -37,87 +38,6 @@ func TestNormalizeCardNumber(t *testing.T) {
 	}
 }
 
-func TestConvertToDigits(t *testing.T) {
-	cases := []struct {
-		name           string
-		cardNumber     string
-		expectedResult []int
-		expectError    bool
-	}{
-		{
-			"should-convert-card-number-to-digits",
-			"378282246310005",
-			[]int{3, 7, 8, 2, 8, 2, 2, 4, 6, 3, 1, 0, 0, 0, 5},
-			false,
-		},
-		{
-			"should-return-error-for-invalid-character-dot",
-			"378282246310005.",
-			nil,
-			true,
-		},
-		{
-			"should-return-error-for-invalid-character-with-spaces",
-			"  3782 8224 6310 005 ",
-			nil,
-			true,
-		},
-		{
-			"should-return-error-for-alphabetic-characters",
-			"378282246310005abc",
-			nil,
-			true,
-		},
-	}
-
-	for _, c := range cases {
-		t.Run(c.name, func(t *testing.T) {
-			numbers, err := convertToDigits(c.cardNumber)
-			if c.expectError {
-				assert.Error(t, err)
-			} else {
-				assert.Equal(t, c.expectedResult, numbers)
-			}
-		})
-	}
-}
-
-func TestValidCheckSum(t *testing.T) {
-	cases := []struct {
-		name           string
-		numbers        []int
-		expectedResult bool
-	}{
-		{
-			"should-pass-for-sum-0",
-			[]int{},
-			true,
-		},
-		{
-			"should-pass-for-sum-0-again",
-			[]int{0, 0, 0, 0, 0, 0},
-			true,
-		},
-		{
-			"should-pass-as-divisible-by-10",
-			[]int{3, 7, 8, 2, 8, 2, 2, 4, 6, 3, 1, 0, 0, 0, 5},
-			true,
-		},
-		{
-			"should-not-pass-as-not-divisible-by-10",
-			[]int{5, 2, 3, 7, 2, 5, 1, 6, 2, 4, 7, 7, 8, 1, 3, 2},
-			false,
-		},
-	}
-
-	for _, c := range cases {
-		t.Run(c.name, func(t *testing.T) {
-			numbers := validChecksum(c.numbers)
-			assert.Equal(t, c.expectedResult, numbers)
-		})
-	}
-}
-
 func TestCardValid(t *testing.T) {
 	cases := []struct {
 		name             string
@@ -283,3 +203,141 @@ func TestCardSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestCardSchemaAt(t *testing.T) {
+	cases := []struct {
+		name           string
+		cardNumber     string
+		at             time.Time
+		expectedResult pkg.Schema
+	}{
+		{
+			"should-be-unknown-for-2-series-before-2017",
+			"2221000000000009",
+			time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC),
+			pkg.SchemaUnknown,
+		},
+		{
+			"should-be-master-card-for-2-series-from-2017",
+			"2221000000000009",
+			time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+			pkg.SchemaMasterCard,
+		},
+		{
+			"should-be-master-card-for-5-series-before-2017",
+			"5105105105105100",
+			time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+			pkg.SchemaMasterCard,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schema, err := CardSchemaAt(c.cardNumber, c.at)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedResult, schema)
+
+			// The compiled table of Analyze applies the same effective dates.
+			analysis, err := analyze(defaultCompiledSchemes(), c.cardNumber, c.at)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedResult, analysis.Schema)
+		})
+	}
+}
+
+func TestSchemesEffectiveAtGivenTime(t *testing.T) {
+	before2017 := time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	schema, err := CardSchemaAt("2221000000000009", before2017)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, schema)
+
+	schema, err = LookupCardSchemaAt(DefaultLookup(), "2221000000000009", before2017)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, schema)
+
+	schemes, err := CardSchemesAt("2221000000000009", before2017)
+	assert.NoError(t, err)
+	assert.Empty(t, schemes)
+
+	analysis, err := AnalyzeAt("2221000000000009", before2017)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, analysis.Schema)
+
+	explanation, err := ExplainAt("2221000000000009", before2017)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, explanation.Schema)
+
+	explanation, err = Explain("2221000000000009")
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaMasterCard, explanation.Schema)
+}
+
+func TestCardValidErrorDetails(t *testing.T) {
+	cases := []struct {
+		name          string
+		cardNumber    string
+		expectedError *ValidationError
+	}{
+		{
+			"should-report-empty",
+			"",
+			&ValidationError{Kind: ErrEmpty},
+		},
+		{
+			"should-report-length-of-too-long-number",
+			"11111111111111111111",
+			&ValidationError{Kind: ErrTooLong, Length: 20},
+		},
+		{
+			"should-report-position-of-first-non-digit",
+			"5105-1051-0510",
+			&ValidationError{Kind: ErrNonDigit, Length: 14, Position: 5},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := CardValid(c.cardNumber)
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, c.expectedError, validationErr)
+			assert.ErrorIs(t, err, c.expectedError.Kind)
+		})
+	}
+}
+
+func TestLocateInRaw(t *testing.T) {
+	cases := []struct {
+		name             string
+		raw              string
+		expectedPosition int
+	}{
+		{"should-keep-position-without-removed-characters", "5105-1051-0510", 5},
+		{"should-skip-removed-spaces", "4111 11x1 1111 1111", 8},
+		{"should-skip-leading-whitespace", "  4111 11x1", 10},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			normalized := NormalizeCardNumber(c.raw)
+			_, err := CardValid(normalized)
+			err = LocateInRaw(err, c.raw, normalized)
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, c.expectedPosition, validationErr.Position)
+		})
+	}
+
+	assert.NoError(t, LocateInRaw(nil, "4111", "4111"))
+	assert.Equal(t, ErrEmpty, LocateInRaw(ErrEmpty, "", ""))
+}
+
+func TestCardSchemes(t *testing.T) {
+	schemes, err := CardSchemes("4111111111111111")
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.Schema{pkg.SchemaVisa}, schemes)
+
+	_, err = CardSchemes("")
+	assert.ErrorIs(t, err, ErrEmpty)
+}
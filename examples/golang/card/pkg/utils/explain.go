@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"card/pkg"
+)
+
+// iinLength digits identify the issuer and are the only ones left unmasked in explanations.
+const iinLength = 6
+
+// PrefixTrace shows how a single prefix or prefix range was tested.
+type PrefixTrace struct {
+	Prefix    string
+	Tested    string // Leading card digits compared with the prefix, masked beyond the IIN.
+	Effective bool   // Whether the prefix is effective now, a prefix which is not never matches.
+	Matched   bool
+	Error     string
+}
+
+// SchemeTrace shows how a scheme was evaluated; prefixes are tested only when the scheme is effective
+// and the length is allowed.
+type SchemeTrace struct {
+	Scheme        pkg.Schema
+	Lengths       []int
+	Effective     bool
+	LengthMatched bool
+	Prefixes      []PrefixTrace
+	Matched       bool
+}
+
+// LuhnTrace holds the intermediate sums of the checksum. Sums are kept per group instead of per digit,
+// per digit values would disclose the masked digits.
+type LuhnTrace struct {
+	DoubledSum int // Sum of every second digit from the right, doubled and reduced to a single digit.
+	PlainSum   int // Sum of the remaining digits.
+	Total      int
+	Valid      bool
+}
+
+// Explanation tells why a number was classified the way it was.
+type Explanation struct {
+	Number  string // Masked except the IIN.
+	Schemes []SchemeTrace
+	Schema  pkg.Schema
+	Reason  string
+	Luhn    LuhnTrace
+}
+
+// Explain classifies a card number with the built-in table as of now and traces every decision.
+// The input is normalized first, errors are the ones of CardValid.
+func Explain(cardNumber string) (Explanation, error) {
+	return ExplainAt(cardNumber, time.Now())
+}
+
+// ExplainAt is Explain with the ranges effective at the given time.
+func ExplainAt(cardNumber string, at time.Time) (Explanation, error) {
+	return newLookupTable().(*lookupTable).explain(cardNumber, at)
+}
+
+// explain evaluates every scheme, not only up to the first match, so overlapping schemes show up.
+func (lt *lookupTable) explain(cardNumber string, at time.Time) (Explanation, error) {
+	number := NormalizeCardNumber(cardNumber)
+	if err := validateCardNumberLength(number); err != nil {
+		return Explanation{}, err
+	}
+	if !IsDigits(number) {
+		return Explanation{}, LocateInRaw(nonDigitError(number), cardNumber, number)
+	}
+
+	explanation := Explanation{
+		Number: maskExceptIIN(number),
+		Schema: pkg.SchemaUnknown,
+		Luhn:   traceLuhn(number),
+	}
+
+	var winner, winnerPrefix string
+	for _, scheme := range lt.schemes {
+		trace := SchemeTrace{
+			Scheme:        scheme.name,
+			Lengths:       scheme.lengths,
+			Effective:     scheme.period.contains(at),
+			LengthMatched: slices.Contains(scheme.lengths, len(number)),
+		}
+		if trace.Effective && trace.LengthMatched {
+			for _, prefixRange := range scheme.prefixes {
+				prefixTrace := tracePrefix(number, prefixRange)
+				prefixTrace.Effective = scheme.prefixPeriod(prefixRange).contains(at)
+				prefixTrace.Matched = prefixTrace.Matched && prefixTrace.Effective
+				if prefixTrace.Matched && !trace.Matched {
+					trace.Matched = true
+					if winner == "" {
+						winner, winnerPrefix = string(scheme.name), prefixRange
+					} else {
+						explanation.Reason += fmt.Sprintf("; %s prefix %q also matches but comes later in the table", scheme.name, prefixRange)
+						if prefixWidth(prefixRange) > prefixWidth(winnerPrefix) {
+							explanation.Reason += " (table order takes precedence over its more specific prefix)"
+						}
+					}
+				}
+				trace.Prefixes = append(trace.Prefixes, prefixTrace)
+			}
+		}
+		explanation.Schemes = append(explanation.Schemes, trace)
+	}
+
+	if winner == "" {
+		explanation.Reason = "no scheme allows the length and a matching prefix"
+	} else {
+		explanation.Schema = pkg.Schema(winner)
+		explanation.Reason = fmt.Sprintf("%s prefix %q is the first match in table order", winner, winnerPrefix) + explanation.Reason
+	}
+	return explanation, nil
+}
+
+func tracePrefix(number, prefixRange string) PrefixTrace {
+	trace := PrefixTrace{Prefix: prefixRange}
+	width := prefixWidth(prefixRange)
+	if width <= len(number) {
+		trace.Tested = maskExceptIIN(number[:width])
+	}
+
+	matched, err := matchPrefix(number, prefixRange)
+	if err != nil {
+		trace.Error = err.Error()
+	}
+	trace.Matched = matched
+	return trace
+}
+
+// prefixWidth is the number of leading digits matchPrefix compares.
+func prefixWidth(prefixRange string) int {
+	start, _, _ := strings.Cut(prefixRange, "-")
+	return len(start)
+}
+
+func traceLuhn(number string) LuhnTrace {
+	var trace LuhnTrace
+	second := false
+	for p := len(number) - 1; p >= 0; p-- {
+		digit := int(number[p] - '0')
+		if second {
+			digit *= 2
+			trace.DoubledSum += digit/10 + digit%10
+		} else {
+			trace.PlainSum += digit
+		}
+		second = !second
+	}
+	trace.Total = trace.DoubledSum + trace.PlainSum
+	trace.Valid = trace.Total%10 == 0
+	return trace
+}
+
+func maskExceptIIN(number string) string {
+	if len(number) <= iinLength {
+		return number
+	}
+	return number[:iinLength] + strings.Repeat("*", len(number)-iinLength)
+}
+
+func (e Explanation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Number: %s\n", e.Number)
+	for _, scheme := range e.Schemes {
+		if !scheme.Effective {
+			fmt.Fprintf(&sb, "Scheme %s: not effective, skipped\n", scheme.Scheme)
+			continue
+		}
+		if !scheme.LengthMatched {
+			fmt.Fprintf(&sb, "Scheme %s: length %d not in %v, skipped\n", scheme.Scheme, len(e.Number), scheme.Lengths)
+			continue
+		}
+		fmt.Fprintf(&sb, "Scheme %s: length %d allowed\n", scheme.Scheme, len(e.Number))
+		for _, prefix := range scheme.Prefixes {
+			result := map[bool]string{true: "match", false: "no match"}[prefix.Matched]
+			if prefix.Error != "" {
+				result = "error: " + prefix.Error
+			} else if !prefix.Effective {
+				result = "not effective"
+			}
+			fmt.Fprintf(&sb, "  prefix %q: tested %q -> %s\n", prefix.Prefix, prefix.Tested, result)
+		}
+	}
+	fmt.Fprintf(&sb, "Schema: %s (%s)\n", e.Schema, e.Reason)
+	validText := map[bool]string{true: "valid", false: "invalid"}[e.Luhn.Valid]
+	fmt.Fprintf(&sb, "Luhn: doubled digits %d + other digits %d = %d -> %s\n",
+		e.Luhn.DoubledSum, e.Luhn.PlainSum, e.Luhn.Total, validText)
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+//go:build unit
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	explanation, err := Explain("5105 1051 0510 5100")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "510510**********", explanation.Number)
+	assert.Equal(t, pkg.SchemaMasterCard, explanation.Schema)
+	assert.Equal(t, `MasterCard prefix "51-55" is the first match in table order`, explanation.Reason)
+	assert.Equal(t, LuhnTrace{DoubledSum: 7, PlainSum: 13, Total: 20, Valid: true}, explanation.Luhn)
+
+	assert.Len(t, explanation.Schemes, 5)
+	assert.False(t, explanation.Schemes[0].LengthMatched)
+	assert.Empty(t, explanation.Schemes[0].Prefixes)
+
+	maestro := explanation.Schemes[2]
+	assert.True(t, maestro.LengthMatched)
+	assert.False(t, maestro.Matched)
+	assert.Equal(t, []PrefixTrace{
+		{Prefix: "50", Tested: "51", Effective: true},
+		{Prefix: "56-58", Tested: "51", Effective: true},
+		{Prefix: "6", Tested: "5", Effective: true},
+	}, maestro.Prefixes)
+
+	masterCard := explanation.Schemes[4]
+	assert.True(t, masterCard.Matched)
+	assert.Equal(t, []PrefixTrace{
+		{Prefix: "2221-2720", Tested: "5105", Effective: true},
+		{Prefix: "51-55", Tested: "51", Effective: true, Matched: true},
+	}, masterCard.Prefixes)
+}
+
+func TestExplainOverlap(t *testing.T) {
+	table := &lookupTable{schemes: []cardScheme{
+		{name: pkg.SchemaMaestro, prefixes: []string{"6"}, lengths: []int{16}},
+		{name: "Discover", prefixes: []string{"6011"}, lengths: []int{16}},
+	}}
+
+	explanation, err := table.explain("6011111111111117", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaMaestro, explanation.Schema)
+	assert.Equal(t, `Maestro prefix "6" is the first match in table order; `+
+		`Discover prefix "6011" also matches but comes later in the table `+
+		`(table order takes precedence over its more specific prefix)`, explanation.Reason)
+	assert.True(t, explanation.Schemes[1].Matched)
+}
+
+func TestExplainUnknown(t *testing.T) {
+	explanation, err := Explain("9105105105105100")
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.SchemaUnknown, explanation.Schema)
+	assert.Equal(t, "no scheme allows the length and a matching prefix", explanation.Reason)
+	assert.False(t, explanation.Luhn.Valid)
+}
+
+func TestExplainErrors(t *testing.T) {
+	_, err := Explain("")
+	assert.ErrorIs(t, err, ErrEmpty)
+
+	_, err = Explain("5105105105105100.")
+	assert.ErrorIs(t, err, ErrNonDigit)
+}
+
+func TestExplanationString(t *testing.T) {
+	explanation, err := Explain("378282246310005")
+	assert.NoError(t, err)
+	assert.Equal(t, `Number: 378282*********
+Scheme American Express: length 15 allowed
+  prefix "34": tested "37" -> no match
+  prefix "37": tested "37" -> match
+Scheme JCB: length 15 not in [16 17 18 19], skipped
+Scheme Maestro: length 15 allowed
+  prefix "50": tested "37" -> no match
+  prefix "56-58": tested "37" -> no match
+  prefix "6": tested "3" -> no match
+Scheme Visa: length 15 not in [13 16 19], skipped
+Scheme MasterCard: length 15 not in [16], skipped
+Schema: American Express (American Express prefix "37" is the first match in table order)
+Luhn: doubled digits 27 + other digits 33 = 60 -> valid
+`, explanation.String())
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"card/pkg"
+)
+
+type LintSeverity string
+
+const (
+	// LintError marks a table which fails or misbehaves at match time.
+	LintError LintSeverity = "error"
+	// LintWarning marks a working table which most likely does not classify as intended.
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is a single finding of a scheme table check.
+type LintIssue struct {
+	Severity LintSeverity
+	Scheme   pkg.Schema
+	Prefix   string // Empty for issues of the scheme itself.
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	if i.Prefix == "" {
+		return fmt.Sprintf("%s: %s: %s", i.Severity, i.Scheme, i.Message)
+	}
+	return fmt.Sprintf("%s: %s %q: %s", i.Severity, i.Scheme, i.Prefix, i.Message)
+}
+
+// LintDefaultSchemes checks the built-in scheme table.
+func LintDefaultSchemes() []LintIssue {
+	return lintSchemes(defaultCardSchemes())
+}
+
+// LintLookupFile checks a scheme table file, see LintLookupTable.
+func LintLookupFile(path string) ([]LintIssue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LintLookupTable(f)
+}
+
+// LintLookupTable checks a JSON scheme table. Only unreadable JSON is returned as error,
+// everything LoadLookupTable would reject is reported as a LintError issue.
+func LintLookupTable(r io.Reader) ([]LintIssue, error) {
+	table, _, err := parseLookupTable(r)
+	if err != nil {
+		return nil, err
+	}
+	return lintSchemes(table.schemes), nil
+}
+
+type lintedRange struct {
+	prefix string
+	r      compiledRange
+	period period
+}
+
+// lintSchemes reports malformed entries, prefixes which cannot match and ranges overlapping
+// between schemes; schemes are matched in order, so an overlap is decided by the earlier scheme.
+func lintSchemes(schemes []cardScheme) []LintIssue {
+	var issues []LintIssue
+	report := func(severity LintSeverity, scheme pkg.Schema, prefix, format string, args ...any) {
+		issues = append(issues, LintIssue{severity, scheme, prefix, fmt.Sprintf(format, args...)})
+	}
+
+	ranges := make([][]lintedRange, len(schemes))
+	for i, scheme := range schemes {
+		name := scheme.name
+		if name == "" {
+			name = pkg.Schema(fmt.Sprintf("#%d", i+1))
+			report(LintError, name, "", "missing name")
+		}
+		if len(scheme.prefixes) == 0 {
+			report(LintError, name, "", "no prefixes")
+		}
+		if len(scheme.lengths) == 0 {
+			report(LintError, name, "", "no lengths")
+		}
+		for _, length := range scheme.lengths {
+			if length < 1 || length > MaxCardLength {
+				report(LintError, name, "", "length %d out of range 1-%d", length, MaxCardLength)
+			}
+		}
+		if scheme.period.empty() {
+			report(LintError, name, "", "effective period %s is empty", scheme.period)
+		}
+
+		for k, prefixRange := range scheme.prefixes {
+			if slices.Contains(scheme.prefixes[:k], prefixRange) {
+				report(LintError, name, prefixRange, "duplicate prefix")
+				continue
+			}
+			if own := scheme.prefixPeriods[prefixRange]; own.empty() {
+				report(LintError, name, prefixRange, "effective period %s is empty", own)
+				continue
+			} else if !scheme.period.empty() && scheme.prefixPeriod(prefixRange).empty() {
+				report(LintWarning, name, prefixRange, "never matches: effective period %s is outside the scheme's %s",
+					own, scheme.period)
+				continue
+			}
+			r, err := compileRange(prefixRange)
+			if err != nil {
+				report(LintError, name, prefixRange, "malformed prefix: %v", err)
+				continue
+			}
+			if start, end, isRange := strings.Cut(prefixRange, "-"); isRange && len(start) != len(end) {
+				report(LintError, name, prefixRange, "range bounds differ in width")
+				continue
+			}
+			if r.start > r.end {
+				report(LintError, name, prefixRange, "reversed range")
+				continue
+			}
+			if len(scheme.lengths) > 0 && r.width > slices.Max(scheme.lengths) {
+				report(LintWarning, name, prefixRange, "never matches: longer than the longest allowed length %d",
+					slices.Max(scheme.lengths))
+				continue
+			}
+			ranges[i] = append(ranges[i], lintedRange{prefixRange, r, scheme.prefixPeriod(prefixRange)})
+		}
+	}
+
+	for j := range schemes {
+		for i := 0; i < j; i++ {
+			shared := sharedLengths(schemes[i].lengths, schemes[j].lengths)
+			if len(shared) == 0 {
+				continue
+			}
+			for _, later := range ranges[j] {
+				for _, earlier := range ranges[i] {
+					if !rangesOverlap(earlier.r, later.r) || !earlier.period.overlaps(later.period) {
+						continue
+					}
+					if rangeContains(earlier.r, later.r) && len(shared) == len(schemes[j].lengths) {
+						report(LintWarning, schemes[j].name, later.prefix, "shadowed by %s %q, which is matched first",
+							schemes[i].name, earlier.prefix)
+					} else {
+						report(LintWarning, schemes[j].name, later.prefix, "overlaps with %s %q for lengths %v",
+							schemes[i].name, earlier.prefix, shared)
+					}
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+func sharedLengths(a, b []int) []int {
+	var shared []int
+	for _, length := range b {
+		if slices.Contains(a, length) {
+			shared = append(shared, length)
+		}
+	}
+	return shared
+}
+
+// widen returns the bounds of all numbers of the given width starting with the range.
+func widen(r compiledRange, width int) (int, int) {
+	scale := pow10(width - r.width)
+	return r.start * scale, (r.end+1)*scale - 1
+}
+
+func rangesOverlap(a, b compiledRange) bool {
+	width := max(a.width, b.width)
+	aStart, aEnd := widen(a, width)
+	bStart, bEnd := widen(b, width)
+	return aStart <= bEnd && bStart <= aEnd
+}
+
+func rangeContains(outer, inner compiledRange) bool {
+	width := max(outer.width, inner.width)
+	outerStart, outerEnd := widen(outer, width)
+	innerStart, innerEnd := widen(inner, width)
+	return outerStart <= innerStart && innerEnd <= outerEnd
+}
@@ -0,0 +1,132 @@
+//go:build unit
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintDefaultSchemes(t *testing.T) {
+	assert.Empty(t, LintDefaultSchemes())
+}
+
+func TestLintLookupTable(t *testing.T) {
+	cases := []struct {
+		name           string
+		schemes        string
+		expectedIssues []string
+	}{
+		{
+			"should-report-nothing-for-clean-table",
+			`{"name": "Visa", "prefixes": ["4"], "lengths": [16]},
+			 {"name": "MasterCard", "prefixes": ["51-55"], "lengths": [16]}`,
+			nil,
+		},
+		{
+			"should-report-syntax-errors",
+			`{"name": "JCB", "prefixes": ["3528-", "35x", "1-2-3", "123456789"], "lengths": [16]}`,
+			[]string{
+				`error: JCB "3528-": malformed prefix: missing range bound`,
+				`error: JCB "35x": malformed prefix: non-digit characters`,
+				`error: JCB "1-2-3": malformed prefix: non-digit characters`,
+				`error: JCB "123456789": malformed prefix: longer than 8 digits`,
+			},
+		},
+		{
+			"should-report-reversed-and-mismatched-ranges",
+			`{"name": "X", "prefixes": ["9-1", "51-5599"], "lengths": [16]}`,
+			[]string{
+				`error: X "9-1": reversed range`,
+				`error: X "51-5599": range bounds differ in width`,
+			},
+		},
+		{
+			"should-report-scheme-errors",
+			`{"prefixes": [], "lengths": [0]}`,
+			[]string{
+				`error: #1: missing name`,
+				`error: #1: no prefixes`,
+				`error: #1: length 0 out of range 1-19`,
+			},
+		},
+		{
+			"should-report-prefix-longer-than-lengths",
+			`{"name": "Short", "prefixes": ["12345678"], "lengths": [6, 7]}`,
+			[]string{
+				`warning: Short "12345678": never matches: longer than the longest allowed length 7`,
+			},
+		},
+		{
+			"should-report-shadowed-range",
+			`{"name": "Maestro", "prefixes": ["6"], "lengths": [16, 19]},
+			 {"name": "Discover", "prefixes": ["6011"], "lengths": [16]}`,
+			[]string{
+				`warning: Discover "6011": shadowed by Maestro "6", which is matched first`,
+			},
+		},
+		{
+			"should-report-partial-overlap",
+			`{"name": "A", "prefixes": ["50-52"], "lengths": [16]},
+			 {"name": "B", "prefixes": ["5100-5300"], "lengths": [16, 19]}`,
+			[]string{
+				`warning: B "5100-5300": overlaps with A "50-52" for lengths [16]`,
+			},
+		},
+		{
+			"should-report-empty-periods",
+			`{"name": "X", "prefixes": ["4", {"range": "5", "effectiveFrom": "2020-01-01", "effectiveUntil": "2019-01-01"}],
+			  "lengths": [16], "effectiveFrom": "2020-01-01", "effectiveUntil": "2020-01-01"}`,
+			[]string{
+				`error: X: effective period 2020-01-01 to 2020-01-01 is empty`,
+				`error: X "5": effective period 2020-01-01 to 2019-01-01 is empty`,
+			},
+		},
+		{
+			"should-report-prefix-outside-scheme-period",
+			`{"name": "X", "prefixes": [{"range": "4", "effectiveFrom": "2021-01-01"}], "lengths": [16], "effectiveUntil": "2020-01-01"}`,
+			[]string{
+				`warning: X "4": never matches: effective period 2021-01-01 to open is outside the scheme's open to 2020-01-01`,
+			},
+		},
+		{
+			"should-report-duplicate-prefix",
+			`{"name": "X", "prefixes": ["4", {"range": "4", "effectiveFrom": "2021-01-01"}], "lengths": [16]}`,
+			[]string{
+				`error: X "4": duplicate prefix`,
+			},
+		},
+		{
+			"should-ignore-overlap-of-different-periods",
+			`{"name": "Old", "prefixes": [{"range": "9900-9999", "effectiveUntil": "2020-01-01"}], "lengths": [16]},
+			 {"name": "New", "prefixes": ["99"], "lengths": [16], "effectiveFrom": "2020-01-01"}`,
+			nil,
+		},
+		{
+			"should-ignore-overlap-of-different-lengths",
+			`{"name": "American Express", "prefixes": ["34"], "lengths": [15]},
+			 {"name": "JCB", "prefixes": ["3400-3599"], "lengths": [16]}`,
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			issues, err := LintLookupTable(strings.NewReader(`{"schemes": [` + c.schemes + `]}`))
+			assert.NoError(t, err)
+
+			var messages []string
+			for _, issue := range issues {
+				messages = append(messages, issue.String())
+			}
+			assert.Equal(t, c.expectedIssues, messages)
+		})
+	}
+}
+
+func TestLintLookupTableMalformedJSON(t *testing.T) {
+	_, err := LintLookupTable(strings.NewReader(`{"schemes": [`))
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"card/pkg"
 )
@@ -12,56 +13,90 @@ type CardLookup interface {
 	Match(cardNumber string) (pkg.Schema, bool, error)
 }
 
+// BINLookup is a CardLookup which also knows issuer data, such as a BIN database.
+// BINInfo returns nil for numbers it has no data for.
+type BINLookup interface {
+	CardLookup
+	BINInfo(cardNumber string) (*pkg.BINInfo, error)
+}
+
+// TimedLookup is a CardLookup whose ranges change over time. Match classifies as of now,
+// MatchAt as of a given time, e.g. the date of a historical transaction.
+type TimedLookup interface {
+	CardLookup
+	MatchAt(cardNumber string, at time.Time) (pkg.Schema, bool, error)
+}
+
 type cardScheme struct {
-	name     pkg.Schema
-	prefixes []string // Ranges like "34", "37", "3528-3589"
-	lengths  []int    // Possible lengths like 15, 16, etc.
+	name          pkg.Schema
+	prefixes      []string          // Ranges like "34", "37", "3528-3589"
+	lengths       []int             // Possible lengths like 15, 16, etc.
+	period        period            // When the scheme is effective, always when zero.
+	prefixPeriods map[string]period // When single prefixes are effective, within the scheme period.
 }
 
 type lookupTable struct {
 	schemes []cardScheme
 }
 
+// DefaultLookup returns the built-in scheme table. It is read-only and safe for concurrent use.
+func DefaultLookup() CardLookup {
+	return newLookupTable()
+}
+
 func newLookupTable() CardLookup {
-	return &lookupTable{
-		schemes: []cardScheme{
-			{
-				name:     pkg.SchemaAmericanExpress,
-				prefixes: []string{"34", "37"},
-				lengths:  []int{15},
-			},
-			{
-				name:     pkg.SchemaJCB,
-				prefixes: []string{"3528-3589"},
-				lengths:  []int{16, 17, 18, 19},
-			},
-			{
-				name:     pkg.SchemaMaestro,
-				prefixes: []string{"50", "56-58", "6"},
-				lengths:  []int{12, 13, 14, 15, 16, 17, 18, 19},
-			},
-			{
-				name:     pkg.SchemaVisa,
-				prefixes: []string{"4"},
-				lengths:  []int{13, 16, 19},
-			},
-			{
-				name:     pkg.SchemaMasterCard,
-				prefixes: []string{"2221-2720", "51-55"},
-				lengths:  []int{16},
+	return &lookupTable{schemes: defaultCardSchemes()}
+}
+
+func defaultCardSchemes() []cardScheme {
+	return []cardScheme{
+		{
+			name:     pkg.SchemaAmericanExpress,
+			prefixes: []string{"34", "37"},
+			lengths:  []int{15},
+		},
+		{
+			name:     pkg.SchemaJCB,
+			prefixes: []string{"3528-3589"},
+			lengths:  []int{16, 17, 18, 19},
+		},
+		{
+			name:     pkg.SchemaMaestro,
+			prefixes: []string{"50", "56-58", "6"},
+			lengths:  []int{12, 13, 14, 15, 16, 17, 18, 19},
+		},
+		{
+			name:     pkg.SchemaVisa,
+			prefixes: []string{"4"},
+			lengths:  []int{13, 16, 19},
+		},
+		{
+			name:     pkg.SchemaMasterCard,
+			prefixes: []string{"2221-2720", "51-55"},
+			lengths:  []int{16},
+			prefixPeriods: map[string]period{
+				// The 2-series was opened for issuing in 2017.
+				"2221-2720": {from: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
 			},
 		},
 	}
 }
 
 func (lt *lookupTable) Match(cardNumber string) (pkg.Schema, bool, error) {
+	return lt.MatchAt(cardNumber, time.Now())
+}
+
+func (lt *lookupTable) MatchAt(cardNumber string, at time.Time) (pkg.Schema, bool, error) {
 	cardLength := len(cardNumber)
 	for _, scheme := range lt.schemes {
-		if !slices.Contains(scheme.lengths, cardLength) {
+		if !slices.Contains(scheme.lengths, cardLength) || !scheme.period.contains(at) {
 			continue
 		}
 
 		for _, prefixRange := range scheme.prefixes {
+			if !scheme.prefixPeriod(prefixRange).contains(at) {
+				continue
+			}
 			if ok, err := matchPrefix(cardNumber, prefixRange); err != nil {
 				return "", false, err
 			} else if ok {
@@ -72,6 +107,37 @@ func (lt *lookupTable) Match(cardNumber string) (pkg.Schema, bool, error) {
 	return "", false, nil
 }
 
+// MatchAll returns every scheme matching the number at the given time, in table order.
+// Co-badged cards match more than one.
+func (lt *lookupTable) MatchAll(cardNumber string, at time.Time) ([]pkg.Schema, error) {
+	var schemes []pkg.Schema
+	cardLength := len(cardNumber)
+	for _, scheme := range lt.schemes {
+		if !slices.Contains(scheme.lengths, cardLength) || !scheme.period.contains(at) {
+			continue
+		}
+
+		for _, prefixRange := range scheme.prefixes {
+			if !scheme.prefixPeriod(prefixRange).contains(at) {
+				continue
+			}
+			if ok, err := matchPrefix(cardNumber, prefixRange); err != nil {
+				return nil, err
+			} else if ok {
+				schemes = append(schemes, scheme.name)
+				break
+			}
+		}
+	}
+	return schemes, nil
+}
+
+// MatchPrefix checks if a card number matches a given prefix or prefix range,
+// using the same syntax as the scheme table ("34", "3528-3589").
+func MatchPrefix(cardNumber, prefixRange string) (bool, error) {
+	return matchPrefix(cardNumber, prefixRange)
+}
+
 // matchPrefix checks if a card number matches a given prefix or prefix range.
 func matchPrefix(cardNumber, prefixRange string) (bool, error) {
 	if strings.Contains(prefixRange, "-") {
@@ -86,6 +152,9 @@ func matchPrefix(cardNumber, prefixRange string) (bool, error) {
 			return false, err
 		}
 		prefixLength := len(parts[0])
+		if len(cardNumber) < prefixLength {
+			return false, nil
+		}
 		cardPrefix, err := strconv.Atoi(cardNumber[:prefixLength])
 		if err != nil {
 			return false, err
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"card/pkg"
+)
+
+// lookupFile is the JSON representation of a scheme table:
+//
+//	{"version": "2024-06", "schemes": [{"name": "Visa", "prefixes": ["4"], "lengths": [13, 16, 19]}]}
+//
+// Schemes are matched in file order, like the built-in table. Schemes and prefixes may be limited
+// to effective dates, from (inclusive) until (exclusive), as in
+//
+//	{"name": "MasterCard", "prefixes": [{"range": "2221-2720", "effectiveFrom": "2017-01-01"}, "51-55"],
+//	 "lengths": [16], "effectiveUntil": "2099-01-01"}
+type lookupFile struct {
+	Version string           `json:"version"`
+	Schemes []lookupFileItem `json:"schemes"`
+}
+
+type lookupFileItem struct {
+	Name     pkg.Schema         `json:"name"`
+	Prefixes []lookupFilePrefix `json:"prefixes"`
+	Lengths  []int              `json:"lengths"`
+	lookupFilePeriod
+}
+
+// lookupFilePrefix is a prefix range, either a plain string or an object carrying effective dates.
+type lookupFilePrefix struct {
+	Range string `json:"range"`
+	lookupFilePeriod
+}
+
+type lookupFilePeriod struct {
+	EffectiveFrom  string `json:"effectiveFrom,omitempty"`  // YYYY-MM-DD, UTC.
+	EffectiveUntil string `json:"effectiveUntil,omitempty"` // YYYY-MM-DD, UTC.
+}
+
+func (p *lookupFilePrefix) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*p = lookupFilePrefix{}
+		return json.Unmarshal(data, &p.Range)
+	}
+
+	// The alias drops this method, so decoding the object does not recurse.
+	type prefix lookupFilePrefix
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode((*prefix)(p))
+}
+
+func (p lookupFilePeriod) parse() (period, error) {
+	var (
+		parsed period
+		err    error
+	)
+	if p.EffectiveFrom != "" {
+		if parsed.from, err = time.Parse(time.DateOnly, p.EffectiveFrom); err != nil {
+			return period{}, fmt.Errorf("invalid effectiveFrom: %w", err)
+		}
+	}
+	if p.EffectiveUntil != "" {
+		if parsed.until, err = time.Parse(time.DateOnly, p.EffectiveUntil); err != nil {
+			return period{}, fmt.Errorf("invalid effectiveUntil: %w", err)
+		}
+	}
+	return parsed, nil
+}
+
+// LoadLookupFile reads a scheme table file, see LoadLookupTable.
+func LoadLookupFile(path string) (CardLookup, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return LoadLookupTable(bytes.NewReader(data))
+}
+
+// LoadLookupTable parses and fully validates a JSON scheme table.
+// It returns the table version, a content hash when the file does not declare one.
+func LoadLookupTable(r io.Reader) (CardLookup, string, error) {
+	table, version, err := parseLookupTable(r)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := table.validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid scheme table: %w", err)
+	}
+	return table, version, nil
+}
+
+func parseLookupTable(r io.Reader) (*lookupTable, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file lookupFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, "", fmt.Errorf("invalid scheme table: %w", err)
+	}
+
+	table := &lookupTable{schemes: make([]cardScheme, 0, len(file.Schemes))}
+	for _, item := range file.Schemes {
+		scheme := cardScheme{name: item.Name, lengths: item.Lengths}
+		if scheme.period, err = item.parse(); err != nil {
+			return nil, "", fmt.Errorf("invalid scheme table: scheme %s: %w", item.Name, err)
+		}
+		for _, prefix := range item.Prefixes {
+			scheme.prefixes = append(scheme.prefixes, prefix.Range)
+			if prefix.lookupFilePeriod == (lookupFilePeriod{}) {
+				continue
+			}
+			p, err := prefix.parse()
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid scheme table: scheme %s: prefix %q: %w", item.Name, prefix.Range, err)
+			}
+			if scheme.prefixPeriods == nil {
+				scheme.prefixPeriods = map[string]period{}
+			}
+			scheme.prefixPeriods[prefix.Range] = p
+		}
+		table.schemes = append(table.schemes, scheme)
+	}
+
+	version := file.Version
+	if version == "" {
+		sum := sha256.Sum256(data)
+		version = hex.EncodeToString(sum[:6])
+	}
+	return table, version, nil
+}
+
+// validate rejects tables which would fail or misbehave at match time,
+// warnings of the linter do not prevent loading.
+func (lt *lookupTable) validate() error {
+	if len(lt.schemes) == 0 {
+		return errors.New("no schemes")
+	}
+	for _, issue := range lintSchemes(lt.schemes) {
+		if issue.Severity == LintError {
+			return errors.New(issue.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+//go:build unit
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadLookupTable(t *testing.T) {
+	lookup, version, err := LoadLookupTable(strings.NewReader(`{
+		"version": "2024-06",
+		"schemes": [
+			{"name": "Visa", "prefixes": ["4"], "lengths": [16]},
+			{"name": "Test Network", "prefixes": ["9900-9999"], "lengths": [16]}
+		]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06", version)
+
+	schema, matched, err := lookup.Match("9912345678901234")
+	assert.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, pkg.Schema("Test Network"), schema)
+}
+
+func TestLoadLookupTableEffectiveDates(t *testing.T) {
+	lookup, _, err := LoadLookupTable(strings.NewReader(`{
+		"schemes": [
+			{"name": "Old Network", "prefixes": ["99"], "lengths": [16], "effectiveUntil": "2020-01-01"},
+			{"name": "New Network", "prefixes": [{"range": "9900-9949", "effectiveFrom": "2020-01-01"}], "lengths": [16]}
+		]
+	}`))
+	assert.NoError(t, err)
+	timed, ok := lookup.(TimedLookup)
+	assert.True(t, ok)
+
+	cases := []struct {
+		name           string
+		at             time.Time
+		cardNumber     string
+		expectedSchema pkg.Schema
+		expectedMatch  bool
+	}{
+		{
+			"should-match-retired-range-before-until",
+			time.Date(2019, 12, 31, 23, 59, 59, 0, time.UTC),
+			"9912345678901234",
+			"Old Network",
+			true,
+		},
+		{
+			"should-match-reassigned-range-from-effective-date",
+			time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			"9912345678901234",
+			"New Network",
+			true,
+		},
+		{
+			"should-not-match-retired-range-outside-reassignment",
+			time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			"9999345678901234",
+			"",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schema, matched, err := timed.MatchAt(c.cardNumber, c.at)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expectedMatch, matched)
+			assert.Equal(t, c.expectedSchema, schema)
+		})
+	}
+}
+
+func TestLoadLookupTableHashVersion(t *testing.T) {
+	table := `{"schemes": [{"name": "Visa", "prefixes": ["4"], "lengths": [16]}]}`
+
+	_, first, err := LoadLookupTable(strings.NewReader(table))
+	assert.NoError(t, err)
+	assert.Len(t, first, 12)
+
+	_, second, err := LoadLookupTable(strings.NewReader(table))
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestLoadLookupTableErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		table string
+	}{
+		{"should-return-error-for-malformed-json", `{"schemes": [`},
+		{"should-return-error-for-unknown-field", `{"schemes": [], "extra": 1}`},
+		{"should-return-error-for-no-schemes", `{"schemes": []}`},
+		{"should-return-error-for-missing-name", `{"schemes": [{"prefixes": ["4"], "lengths": [16]}]}`},
+		{"should-return-error-for-missing-prefixes", `{"schemes": [{"name": "Visa", "lengths": [16]}]}`},
+		{"should-return-error-for-length-out-of-range", `{"schemes": [{"name": "Visa", "prefixes": ["4"], "lengths": [20]}]}`},
+		{"should-return-error-for-open-range", `{"schemes": [{"name": "JCB", "prefixes": ["3528-"], "lengths": [16]}]}`},
+		{"should-return-error-for-reversed-range", `{"schemes": [{"name": "X", "prefixes": ["9-1"], "lengths": [16]}]}`},
+		{"should-return-error-for-malformed-date", `{"schemes": [{"name": "X", "prefixes": ["4"], "lengths": [16], "effectiveFrom": "2020-13-01"}]}`},
+		{"should-return-error-for-unknown-prefix-field", `{"schemes": [{"name": "X", "prefixes": [{"range": "4", "from": "2020-01-01"}], "lengths": [16]}]}`},
+		{"should-return-error-for-empty-period", `{"schemes": [{"name": "X", "prefixes": ["4"], "lengths": [16], "effectiveFrom": "2020-01-01", "effectiveUntil": "2019-01-01"}]}`},
+		{"should-return-error-for-non-digit-prefix", `{"schemes": [{"name": "X", "prefixes": ["4a"], "lengths": [16]}]}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := LoadLookupTable(strings.NewReader(c.table))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -4,6 +4,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"card/pkg"
 
@@ -92,6 +93,13 @@ func TestMatchPrefix(t *testing.T) {
 			false,
 			false,
 		},
+		{
+			"should-not-match-when-number-is-shorter-than-range",
+			"35",
+			"3528-3589",
+			false,
+			false,
+		},
 		{
 			"should-return-error-for-wrong-start-range",
 			"2030111333300000",
@@ -119,3 +127,19 @@ func TestMatchPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupTableMatchAll(t *testing.T) {
+	table := &lookupTable{schemes: []cardScheme{
+		{name: pkg.SchemaMaestro, prefixes: []string{"6"}, lengths: []int{16}},
+		{name: "Discover", prefixes: []string{"6011"}, lengths: []int{16}},
+		{name: "Short", prefixes: []string{"6"}, lengths: []int{13}},
+	}}
+
+	schemes, err := table.MatchAll("6011111111111117", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.Schema{pkg.SchemaMaestro, "Discover"}, schemes)
+
+	schemes, err = table.MatchAll("9011111111111117", time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, schemes)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// period limits a scheme or prefix to the times from (inclusive) until (exclusive);
+// a zero bound leaves that side open.
+type period struct {
+	from, until time.Time
+}
+
+func (p period) contains(t time.Time) bool {
+	return (p.from.IsZero() || !t.Before(p.from)) && (p.until.IsZero() || t.Before(p.until))
+}
+
+func (p period) overlaps(o period) bool {
+	return (p.until.IsZero() || o.from.IsZero() || o.from.Before(p.until)) &&
+		(o.until.IsZero() || p.from.IsZero() || p.from.Before(o.until))
+}
+
+// intersect narrows p to o, e.g. a prefix period to the period of its scheme.
+func (p period) intersect(o period) period {
+	if p.from.IsZero() || o.from.After(p.from) {
+		p.from = o.from
+	}
+	if p.until.IsZero() || (!o.until.IsZero() && o.until.Before(p.until)) {
+		p.until = o.until
+	}
+	return p
+}
+
+func (p period) empty() bool {
+	return !p.from.IsZero() && !p.until.IsZero() && !p.from.Before(p.until)
+}
+
+func (p period) String() string {
+	bound := func(t time.Time) string {
+		if t.IsZero() {
+			return "open"
+		}
+		return t.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s to %s", bound(p.from), bound(p.until))
+}
+
+// prefixPeriod is the period the prefix is effective in, within the period of its scheme.
+func (s cardScheme) prefixPeriod(prefixRange string) period {
+	return s.prefixPeriods[prefixRange].intersect(s.period)
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"card/pkg"
+)
+
+// ReloadStatus describes the table in use and the outcome of the latest load attempt.
+type ReloadStatus struct {
+	Version     string    // Version of the table in use.
+	LoadedAt    time.Time // When the table in use was loaded.
+	LastAttempt time.Time // When a load was last attempted.
+	LastError   error     // Error of the last attempt, nil when it succeeded.
+}
+
+// LookupLoader reads a lookup and its version from a file. It must validate the data fully,
+// as a table it returns without error replaces the one in use. A nil table is a failed load.
+type LookupLoader func(path string) (CardLookup, string, error)
+
+// ReloadableLookup is a CardLookup backed by a file which is reloaded while in use.
+// Matching never blocks on a reload: the new table is swapped in atomically once it is valid,
+// a table failing to load leaves the previous one in place.
+type ReloadableLookup struct {
+	path   string
+	load   LookupLoader
+	notify func(ReloadStatus)
+	clock  func() time.Time
+
+	current atomic.Pointer[CardLookup]
+
+	mu      sync.Mutex // Serializes reloads and guards the fields below.
+	status  ReloadStatus
+	modTime time.Time
+	size    int64
+}
+
+type ReloadOption func(*ReloadableLookup)
+
+// WithLoader replaces LoadLookupFile, e.g. to load BIN data.
+func WithLoader(load LookupLoader) ReloadOption {
+	return func(rl *ReloadableLookup) {
+		rl.load = load
+	}
+}
+
+// WithReloadNotify registers a callback invoked after every load attempt.
+func WithReloadNotify(notify func(ReloadStatus)) ReloadOption {
+	return func(rl *ReloadableLookup) {
+		rl.notify = notify
+	}
+}
+
+// WithReloadClock sets the source of the load times in ReloadStatus, time.Now by default.
+// A nil clock is ignored.
+func WithReloadClock(clock func() time.Time) ReloadOption {
+	return func(rl *ReloadableLookup) {
+		if clock != nil {
+			rl.clock = clock
+		}
+	}
+}
+
+// NewReloadableLookup loads the file once, failing if it is not valid.
+func NewReloadableLookup(path string, opts ...ReloadOption) (*ReloadableLookup, error) {
+	rl := &ReloadableLookup{
+		path:  path,
+		load:  LoadLookupFile,
+		clock: time.Now,
+	}
+	for _, o := range opts {
+		o(rl)
+	}
+
+	if err := rl.Reload(); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
+
+func (rl *ReloadableLookup) Match(cardNumber string) (pkg.Schema, bool, error) {
+	return (*rl.current.Load()).Match(cardNumber)
+}
+
+// MatchAt classifies as of the given time when the loaded table is versioned, see TimedLookup.
+func (rl *ReloadableLookup) MatchAt(cardNumber string, at time.Time) (pkg.Schema, bool, error) {
+	lookup := *rl.current.Load()
+	if timed, ok := lookup.(TimedLookup); ok {
+		return timed.MatchAt(cardNumber, at)
+	}
+	return lookup.Match(cardNumber)
+}
+
+// BINInfo returns the issuer data of the loaded table, nil when it carries none, see BINLookup.
+func (rl *ReloadableLookup) BINInfo(cardNumber string) (*pkg.BINInfo, error) {
+	if bins, ok := (*rl.current.Load()).(BINLookup); ok {
+		return bins.BINInfo(cardNumber)
+	}
+	return nil, nil
+}
+
+// Status returns the version in use and the result of the last load attempt.
+func (rl *ReloadableLookup) Status() ReloadStatus {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.status
+}
+
+// Reload loads the file and swaps the table in if it is valid.
+func (rl *ReloadableLookup) Reload() error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	info, statErr := os.Stat(rl.path)
+	lookup, version, err := rl.load(rl.path)
+	if err == nil && lookup == nil {
+		err = fmt.Errorf("loading %s returned no lookup", rl.path)
+	}
+
+	now := rl.clock()
+	rl.status.LastAttempt = now
+	rl.status.LastError = err
+	if err == nil {
+		rl.current.Store(&lookup)
+		rl.status.Version = version
+		rl.status.LoadedAt = now
+	}
+	// Remember the file state even after a failure, so polling does not retry a broken file
+	// until it changes again.
+	if statErr == nil {
+		rl.modTime, rl.size = info.ModTime(), info.Size()
+	}
+
+	if rl.notify != nil {
+		rl.notify(rl.status)
+	}
+	return err
+}
+
+// Watch polls the file's modification time and size every interval and reloads on change,
+// until ctx is done.
+func (rl *ReloadableLookup) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if rl.changed() {
+				_ = rl.Reload()
+			}
+		}
+	}
+}
+
+// WatchSignal reloads whenever one of the signals (SIGHUP by default) arrives, until ctx is done.
+func (rl *ReloadableLookup) WatchSignal(ctx context.Context, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+
+	rl.watchChannel(ctx, ch)
+}
+
+func (rl *ReloadableLookup) watchChannel(ctx context.Context, ch <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			_ = rl.Reload()
+		}
+	}
+}
+
+func (rl *ReloadableLookup) changed() bool {
+	info, err := os.Stat(rl.path)
+	if err != nil {
+		return false
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return !info.ModTime().Equal(rl.modTime) || info.Size() != rl.size
+}
@@ -0,0 +1,123 @@
+//go:build unit
+
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"card/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTable(t *testing.T, path, version, name string) {
+	table := `{"version": "` + version + `", "schemes": [{"name": "` + name + `", "prefixes": ["4"], "lengths": [16]}]}`
+	assert.NoError(t, os.WriteFile(path, []byte(table), 0o600))
+}
+
+func matchVisa(t *testing.T, lookup CardLookup) pkg.Schema {
+	schema, _, err := lookup.Match("4012888888881881")
+	assert.NoError(t, err)
+	return schema
+}
+
+func TestReloadableLookupReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemes.json")
+	writeTable(t, path, "v1", "Visa")
+
+	now := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+	var notified []ReloadStatus
+	lookup, err := NewReloadableLookup(path, WithReloadNotify(func(s ReloadStatus) {
+		notified = append(notified, s)
+	}), WithReloadClock(func() time.Time { return now }))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", lookup.Status().Version)
+	assert.Equal(t, now, lookup.Status().LoadedAt)
+	assert.Equal(t, pkg.SchemaVisa, matchVisa(t, lookup))
+
+	writeTable(t, path, "v2", "Visa Debit")
+	assert.NoError(t, lookup.Reload())
+	assert.Equal(t, "v2", lookup.Status().Version)
+	assert.Equal(t, pkg.Schema("Visa Debit"), matchVisa(t, lookup))
+
+	loadedAt := now
+	now = now.Add(time.Minute)
+	assert.NoError(t, os.WriteFile(path, []byte(`{"schemes": [{"name": "Broken", "prefixes": ["9-1"], "lengths": [16]}]}`), 0o600))
+	assert.Error(t, lookup.Reload())
+	status := lookup.Status()
+	assert.Equal(t, "v2", status.Version)
+	assert.Equal(t, loadedAt, status.LoadedAt)
+	assert.Equal(t, now, status.LastAttempt)
+	assert.Error(t, status.LastError)
+	assert.Equal(t, pkg.Schema("Visa Debit"), matchVisa(t, lookup))
+
+	assert.Len(t, notified, 3)
+}
+
+func TestNewReloadableLookupInvalid(t *testing.T) {
+	_, err := NewReloadableLookup(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestReloadableLookupRejectsNilLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemes.json")
+	writeTable(t, path, "v1", "Visa")
+	loaded := true
+	load := func(path string) (CardLookup, string, error) {
+		if !loaded {
+			return nil, "", nil
+		}
+		return LoadLookupFile(path)
+	}
+
+	lookup, err := NewReloadableLookup(path, WithLoader(load))
+	assert.NoError(t, err)
+
+	loaded = false
+	assert.Error(t, lookup.Reload())
+	assert.Equal(t, "v1", lookup.Status().Version)
+	assert.Equal(t, pkg.SchemaVisa, matchVisa(t, lookup))
+
+	_, err = NewReloadableLookup(path, WithLoader(load))
+	assert.Error(t, err)
+}
+
+func TestReloadableLookupWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemes.json")
+	writeTable(t, path, "v1", "Visa")
+
+	lookup, err := NewReloadableLookup(path)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go lookup.Watch(ctx, time.Millisecond)
+
+	writeTable(t, path, "v2-longer", "Visa")
+	assert.Eventually(t, func() bool {
+		return lookup.Status().Version == "v2-longer"
+	}, time.Second, time.Millisecond)
+}
+
+func TestReloadableLookupWatchSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemes.json")
+	writeTable(t, path, "v1", "Visa")
+
+	lookup, err := NewReloadableLookup(path)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan os.Signal)
+	go lookup.watchChannel(ctx, ch)
+
+	writeTable(t, path, "v2", "Visa")
+	ch <- os.Interrupt
+	assert.Eventually(t, func() bool {
+		return lookup.Status().Version == "v2"
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import "errors"
+
+// ValidationError details why a card number was rejected, e.g. for user-facing messages.
+// It matches its Kind with errors.Is and keeps the message of the kind.
+type ValidationError struct {
+	Kind     error // ErrEmpty, ErrTooLong or ErrNonDigit.
+	Length   int   // Length of the rejected number.
+	Position int   // 1-based position of the first non-digit character, for ErrNonDigit, see LocateInRaw.
+}
+
+func (e *ValidationError) Error() string {
+	return e.Kind.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Kind
+}
+
+// nonDigitError reports the first character of the number which is not a digit.
+func nonDigitError(cardNumber string) *ValidationError {
+	position := 0
+	for i := 0; i < len(cardNumber); i++ {
+		if cardNumber[i] < '0' || cardNumber[i] > '9' {
+			position = i + 1
+			break
+		}
+	}
+	return &ValidationError{Kind: ErrNonDigit, Length: len(cardNumber), Position: position}
+}
+
+// LocateInRaw maps the position of a non-digit error from the normalized number to the raw input it was
+// normalized from, so messages point at the character the user typed. This is exact for normalizers
+// which only remove characters, like NormalizeCardNumber. Other errors are returned unchanged.
+func LocateInRaw(err error, raw, normalized string) error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Kind != ErrNonDigit || validationErr.Position == 0 {
+		return err
+	}
+
+	// The characters up to the offending one appear in the same order in the raw input.
+	j := 0
+	for i := 0; i < validationErr.Position; i++ {
+		for j < len(raw) && raw[j] != normalized[i] {
+			j++
+		}
+		if j == len(raw) {
+			return err
+		}
+		j++
+	}
+	located := *validationErr
+	located.Position = j
+	return &located
+}
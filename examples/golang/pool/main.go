@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"pool/pkg"
+	"pool/pkg/pool/metrics"
 )
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	poolSize := 1
 	numOfGoroutines := 2
-	if err := pkg.PlayScenarioOfConnectionPool(ctx, poolSize, numOfGoroutines); err != nil {
+	registry, err := metrics.NewRegistry()
+	if err != nil {
+		panic(err)
+	}
+	if err := pkg.PlayScenarioOfConnectionPool(ctx, poolSize, numOfGoroutines, registry); err != nil {
 		cancel()
 		panic(err)
 	}
+	if _, err := registry.WriteTo(os.Stdout); err != nil {
+		panic(err)
+	}
 }
@@ -42,17 +42,16 @@ func (c *connection) Close() error {
 var _ Connection = &connection{}
 
 type ConnectionPool struct {
-	p *pool.Pool
+	p *pool.Pool[Connection]
 }
 
 type ConnectionFactory func() (Connection, error)
 
-func NewConnectionPool(capacity int, connFactory ConnectionFactory, log *zap.Logger) (*ConnectionPool, error) {
-	fact := func() (pool.Poolable, error) {
-		return connFactory()
-	}
-
-	p, err := pool.New(capacity, fact, pool.WithLog(log))
+// NewConnectionPool opens up to capacity connections on demand, the options tune the underlying pool,
+// e.g. pool.WithObserver with a metrics.Collector exports its metrics.
+func NewConnectionPool(capacity int, connFactory ConnectionFactory, log *zap.Logger, opts ...pool.Option[Connection]) (*ConnectionPool, error) {
+	opts = append([]pool.Option[Connection]{pool.WithLog[Connection](log), pool.WithMaxSize[Connection](capacity)}, opts...)
+	p, err := pool.New(pool.Factory[Connection](connFactory), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,17 +59,17 @@ func NewConnectionPool(capacity int, connFactory ConnectionFactory, log *zap.Log
 }
 
 func (cp *ConnectionPool) Get(ctx context.Context) (Connection, error) {
-	p, err := cp.p.Get(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return p.(Connection), nil
+	return cp.p.Get(ctx)
 }
 
 func (cp *ConnectionPool) Put(c Connection) error {
 	return cp.p.Put(c)
 }
 
+func (cp *ConnectionPool) Stats() pool.Stats {
+	return cp.p.Stats()
+}
+
 func (cp *ConnectionPool) Close() {
 	cp.p.Close()
 }
@@ -3,6 +3,9 @@ package pkg
 import (
 	"context"
 
+	"pool/pkg/pool"
+	"pool/pkg/pool/metrics"
+
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -12,12 +15,22 @@ type connectionManager struct {
 	log  *zap.Logger
 }
 
-func newConnectionManager(poolSize int, log *zap.Logger) (*connectionManager, error) {
+// newConnectionManager reports the pool as "connections" to the registry, nil disables metrics.
+func newConnectionManager(poolSize int, log *zap.Logger, registry *metrics.Registry) (*connectionManager, error) {
 	connFactory := func() (Connection, error) {
 		return newConnection(&httpConnection{Name: "httpConnection"}), nil
 	}
 
-	connPool, err := NewConnectionPool(poolSize, connFactory, log)
+	var opts []pool.Option[Connection]
+	if registry != nil {
+		collector, err := registry.Register("connections", nil)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, pool.WithObserver[Connection](collector))
+	}
+
+	connPool, err := NewConnectionPool(poolSize, connFactory, log, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,32 @@
+//go:build unit
+// +build unit
+
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"pool/pkg/pool/metrics"
+)
+
+func TestConnectionManagerMetrics(t *testing.T) {
+	registry, err := metrics.NewRegistry(metrics.WithExpvarPrefix(""))
+	require.NoError(t, err)
+	connMgr, err := newConnectionManager(2, zap.NewNop(), registry)
+	require.NoError(t, err)
+	defer connMgr.pool.Close()
+
+	_, err = connMgr.pool.Get(context.Background())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = registry.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `pool_in_use{pool="connections"} 1`+"\n")
+	require.Equal(t, 1, connMgr.pool.Stats().InUse)
+}
@@ -7,6 +7,8 @@ import (
 	"sync"
 	"time"
 
+	"pool/pkg/pool/metrics"
+
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -22,8 +24,8 @@ Abilities:
 - safe for concurrent use (same as sync.pool)
 */
 
-func PlayScenarioOfConnectionPool(ctx context.Context, poolSize int, numOfGoroutines int) (err error) {
-	connMgr, err := newConnectionManager(poolSize, zap.NewNop())
+func PlayScenarioOfConnectionPool(ctx context.Context, poolSize int, numOfGoroutines int, registry *metrics.Registry) (err error) {
+	connMgr, err := newConnectionManager(poolSize, zap.NewNop(), registry)
 	if err != nil {
 		return errors.Wrap(err, "cannot create connection pool")
 	}
@@ -0,0 +1,141 @@
+package pool
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// idleResource is a resource waiting in the pool since it was created or returned.
+type idleResource[T any] struct {
+	v     T
+	since time.Time
+}
+
+// WithMaxIdleTime closes resources which stayed idle for longer than d, before firewalls or load balancers drop them.
+func WithMaxIdleTime[T any](d time.Duration) Option[T] {
+	return func(pool *Pool[T]) error {
+		if d <= 0 {
+			return errors.New("invalid max idle time settings")
+		}
+		pool.maxIdleTime = d
+		return nil
+	}
+}
+
+// WithMaxLifetime closes resources older than d once they are idle. Their creation time is tracked by the
+// resource itself, which therefore must be of a comparable type, usually a pointer, and distinct.
+// Resources must not change while in use: a struct value modified between Get and Put is not recognized
+// on return, so its creation time is lost and it is never closed for its age.
+func WithMaxLifetime[T any](d time.Duration) Option[T] {
+	return func(pool *Pool[T]) error {
+		if d <= 0 {
+			return errors.New("invalid max lifetime settings")
+		}
+		if !reflect.TypeFor[T]().Comparable() {
+			return errors.New("max lifetime requires comparable resources")
+		}
+		pool.maxLifetime = d
+		return nil
+	}
+}
+
+// WithClock replaces time.Now for expiry decisions.
+func WithClock[T any](now func() time.Time) Option[T] {
+	return func(pool *Pool[T]) error {
+		if now == nil {
+			return errors.New("invalid clock settings")
+		}
+		pool.now = now
+		return nil
+	}
+}
+
+// track records the creation of a resource. It requires p.mu.
+func (p *Pool[T]) track(v T) {
+	if p.maxLifetime > 0 && reflect.ValueOf(any(v)).Comparable() {
+		p.created[any(v)] = p.now()
+	}
+}
+
+// untrack forgets a resource leaving the pool. It requires p.mu.
+func (p *Pool[T]) untrack(v T) {
+	if p.maxLifetime > 0 && reflect.ValueOf(any(v)).Comparable() {
+		delete(p.created, any(v))
+	}
+}
+
+// expired reports idle resources past MaxIdleTime or MaxLifetime. It requires p.mu.
+func (p *Pool[T]) expired(r idleResource[T], now time.Time) bool {
+	if p.maxIdleTime > 0 && now.Sub(r.since) >= p.maxIdleTime {
+		return true
+	}
+	return p.pastLifetime(r.v, now)
+}
+
+// pastLifetime requires p.mu.
+func (p *Pool[T]) pastLifetime(v T, now time.Time) bool {
+	if p.maxLifetime <= 0 || !reflect.ValueOf(any(v)).Comparable() {
+		return false
+	}
+	created, ok := p.created[any(v)]
+	return ok && now.Sub(created) >= p.maxLifetime
+}
+
+// reapInterval checks idle resources twice per the shortest timeout, 0 disables the reaper.
+func (p *Pool[T]) reapInterval() time.Duration {
+	shortest := p.maxIdleTime
+	if shortest == 0 || (p.maxLifetime > 0 && p.maxLifetime < shortest) {
+		shortest = p.maxLifetime
+	}
+	if shortest == 0 {
+		return 0
+	}
+	return max(shortest/2, time.Millisecond)
+}
+
+func (p *Pool[T]) reapLoop(interval time.Duration) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.reap()
+		}
+	}
+}
+
+// reap closes expired idle resources and lets the filler replace them down to MinIdle.
+func (p *Pool[T]) reap() {
+	p.mu.Lock()
+	now := p.now()
+	var expired []T
+	kept := p.idle[:0]
+	for _, r := range p.idle {
+		if p.expired(r, now) {
+			expired = append(expired, r.v)
+			p.destroy(r.v)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	clear(p.idle[len(kept):])
+	p.idle = kept
+	p.mu.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+	p.stats.expiredClosed.Add(int64(len(expired)))
+	p.log.Debug("closing expired resources", zap.Int("count", len(expired)))
+	for _, v := range expired {
+		p.discard(v)
+	}
+	p.requestFill()
+}
@@ -0,0 +1,160 @@
+//go:build unit
+// +build unit
+
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/stretchr/testify/require"
+)
+
+type expiringResource struct {
+	id     int32
+	closed atomic.Bool
+}
+
+func (r *expiringResource) Close() {
+	r.closed.Store(true)
+}
+
+func newExpiringFactory() Factory[*expiringResource] {
+	ids := atomic.NewInt32(0)
+	return func() (*expiringResource, error) {
+		return &expiringResource{id: ids.Inc()}, nil
+	}
+}
+
+func TestPoolGetSkipsExpired(t *testing.T) {
+	start := time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name            string
+		opts            []Option[*expiringResource]
+		idle            time.Duration // Time between Put and the next Get.
+		beforePut       time.Duration // Time between creation and Put.
+		expectedExpired bool
+	}{
+		{
+			"should-reuse-resource-within-max-idle-time",
+			[]Option[*expiringResource]{WithMaxIdleTime[*expiringResource](time.Minute)},
+			59 * time.Second,
+			0,
+			false,
+		},
+		{
+			"should-skip-resource-past-max-idle-time",
+			[]Option[*expiringResource]{WithMaxIdleTime[*expiringResource](time.Minute)},
+			time.Minute,
+			0,
+			true,
+		},
+		{
+			"should-reuse-resource-within-max-lifetime",
+			[]Option[*expiringResource]{WithMaxLifetime[*expiringResource](time.Hour)},
+			30 * time.Minute,
+			29 * time.Minute,
+			false,
+		},
+		{
+			"should-skip-resource-past-max-lifetime",
+			[]Option[*expiringResource]{WithMaxLifetime[*expiringResource](time.Hour)},
+			30 * time.Minute,
+			30 * time.Minute,
+			true,
+		},
+		{
+			"should-close-resource-past-max-lifetime-on-put",
+			[]Option[*expiringResource]{WithMaxLifetime[*expiringResource](time.Hour)},
+			0,
+			time.Hour,
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clock := atomic.NewTime(start)
+			opts := append([]Option[*expiringResource]{WithClock[*expiringResource](clock.Load)}, c.opts...)
+			pool, err := New(newExpiringFactory(), opts...)
+			require.NoError(t, err)
+			defer pool.Close()
+
+			first, err := pool.Get(context.Background())
+			require.NoError(t, err)
+			clock.Store(clock.Load().Add(c.beforePut))
+			require.NoError(t, pool.Put(first))
+			clock.Store(clock.Load().Add(c.idle))
+
+			next, err := pool.Get(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, c.expectedExpired, next != first)
+			require.Eventually(t, func() bool { return first.closed.Load() == c.expectedExpired }, time.Second, time.Millisecond)
+		})
+	}
+}
+
+func TestPoolReap(t *testing.T) {
+	clock := atomic.NewTime(time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC))
+	pool, err := New(newExpiringFactory(),
+		WithClock[*expiringResource](clock.Load),
+		WithMaxIdleTime[*expiringResource](time.Minute),
+		WithMinIdle[*expiringResource](1),
+		WithMaxSize[*expiringResource](3),
+		WithWaitForMinIdle[*expiringResource](time.Second))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	resources := make([]*expiringResource, 3)
+	for i := range resources {
+		resources[i], err = pool.Get(context.Background())
+		require.NoError(t, err)
+	}
+	for _, r := range resources {
+		require.NoError(t, pool.Put(r))
+	}
+	require.Equal(t, 3, idleCount(pool))
+
+	clock.Store(clock.Load().Add(time.Minute))
+	pool.reap()
+
+	for _, r := range resources {
+		require.True(t, r.closed.Load())
+	}
+	// The filler replaces the expired resources down to MinIdle only.
+	require.Eventually(t, func() bool { return idleCount(pool) == 1 }, time.Second, time.Millisecond)
+	pool.mu.Lock()
+	require.Equal(t, 1, pool.open)
+	require.Equal(t, int32(4), pool.idle[0].v.id)
+	pool.mu.Unlock()
+}
+
+func TestPoolReapLoop(t *testing.T) {
+	pool, err := New(newExpiringFactory(), WithMaxIdleTime[*expiringResource](2*time.Millisecond))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	r, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, pool.Put(r))
+
+	require.Eventually(t, r.closed.Load, time.Second, time.Millisecond)
+	require.Equal(t, 0, idleCount(pool))
+}
+
+func TestPoolMaxLifetimeRequiresComparable(t *testing.T) {
+	factory := func() ([]byte, error) {
+		return make([]byte, 8), nil
+	}
+	_, err := New(factory, WithMaxLifetime[[]byte](time.Hour))
+	require.Error(t, err)
+}
+
+func TestPoolRejectsNilClock(t *testing.T) {
+	_, err := New(validFactory, WithClock[*mockPoolable](nil))
+	require.Error(t, err)
+}
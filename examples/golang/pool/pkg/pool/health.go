@@ -0,0 +1,142 @@
+package pool
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultCheckTimeout bounds health checks on return and of idle resources unless WithCheckTimeout is given.
+const DefaultCheckTimeout = 5 * time.Second
+
+// Pinger resources are checked with Ping unless a check is set with WithHealthCheck.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthCheck reports a broken resource, which the pool then closes and replaces.
+type HealthCheck[T any] func(ctx context.Context, v T) error
+
+// WithHealthCheck checks resources with check instead of Ping. Checks only run where enabled by
+// WithTestOnBorrow, WithTestOnReturn or WithIdleCheck, so cheap resources can skip them.
+func WithHealthCheck[T any](check HealthCheck[T]) Option[T] {
+	return func(pool *Pool[T]) error {
+		if check == nil {
+			return errors.New("invalid health check settings")
+		}
+		pool.check = check
+		return nil
+	}
+}
+
+// WithTestOnBorrow checks idle resources in Get, bounded by the caller's context.
+func WithTestOnBorrow[T any]() Option[T] {
+	return func(pool *Pool[T]) error {
+		pool.testOnBorrow = true
+		return nil
+	}
+}
+
+// WithTestOnReturn checks resources in Put.
+func WithTestOnReturn[T any]() Option[T] {
+	return func(pool *Pool[T]) error {
+		pool.testOnReturn = true
+		return nil
+	}
+}
+
+// WithIdleCheck checks all idle resources every interval.
+func WithIdleCheck[T any](interval time.Duration) Option[T] {
+	return func(pool *Pool[T]) error {
+		if interval <= 0 {
+			return errors.New("invalid idle check settings")
+		}
+		pool.idleCheck = interval
+		return nil
+	}
+}
+
+// WithCheckTimeout bounds health checks in Put and of idle resources, which have no caller's context.
+func WithCheckTimeout[T any](d time.Duration) Option[T] {
+	return func(pool *Pool[T]) error {
+		if d <= 0 {
+			return errors.New("invalid check timeout settings")
+		}
+		pool.checkTimeout = d
+		return nil
+	}
+}
+
+// pingResource is the default health check, resources not implementing Pinger are always healthy.
+func pingResource[T any](ctx context.Context, v T) error {
+	if p, ok := any(v).(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// checkWithTimeout runs the health check bounded by the check timeout.
+func (p *Pool[T]) checkWithTimeout(v T) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.checkTimeout)
+	defer cancel()
+	return p.check(ctx, v)
+}
+
+// drop closes a broken resource taken out of the pool and lets the filler replace it.
+func (p *Pool[T]) drop(v T, err error) {
+	p.log.Debug("discarding unhealthy resource", zap.Error(err))
+	p.stats.invalidClosed.Inc()
+
+	p.mu.Lock()
+	p.destroy(v)
+	p.mu.Unlock()
+
+	p.discard(v)
+	p.requestFill()
+}
+
+func (p *Pool[T]) idleCheckLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.idleCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.checkIdle()
+		}
+	}
+}
+
+// checkIdle checks the resources idle at the time of the call, one at a time, so the others stay available.
+// Checked resources keep their idle time.
+func (p *Pool[T]) checkIdle() {
+	p.mu.Lock()
+	n := len(p.idle)
+	p.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		p.mu.Lock()
+		if p.closed || len(p.idle) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		r := p.idle[0]
+		p.idle[0] = idleResource[T]{}
+		p.idle = p.idle[1:]
+		p.mu.Unlock()
+
+		if err := p.checkWithTimeout(r.v); err != nil {
+			p.drop(r.v, err)
+			continue
+		}
+
+		p.mu.Lock()
+		p.putIdle(r)
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,197 @@
+//go:build unit
+// +build unit
+
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type pingableResource struct {
+	id      int32
+	healthy atomic.Bool
+	pings   atomic.Int32
+	closed  atomic.Bool
+}
+
+func (r *pingableResource) Ping(ctx context.Context) error {
+	r.pings.Inc()
+	if !r.healthy.Load() {
+		return errors.New("connection reset")
+	}
+	return nil
+}
+
+func (r *pingableResource) Close() {
+	r.closed.Store(true)
+}
+
+func newPingFactory() Factory[*pingableResource] {
+	ids := atomic.NewInt32(0)
+	return func() (*pingableResource, error) {
+		r := &pingableResource{id: ids.Inc()}
+		r.healthy.Store(true)
+		return r, nil
+	}
+}
+
+func TestPoolHealthChecks(t *testing.T) {
+	cases := []struct {
+		name           string
+		opts           []Option[*pingableResource]
+		healthy        bool
+		expectedPings  int32
+		expectedReused bool
+	}{
+		{
+			"should-skip-checks-by-default",
+			nil,
+			false,
+			0,
+			true,
+		},
+		{
+			"should-reuse-healthy-resource-on-borrow",
+			[]Option[*pingableResource]{WithTestOnBorrow[*pingableResource]()},
+			true,
+			1,
+			true,
+		},
+		{
+			"should-replace-unhealthy-resource-on-borrow",
+			[]Option[*pingableResource]{WithTestOnBorrow[*pingableResource]()},
+			false,
+			1,
+			false,
+		},
+		{
+			"should-reuse-healthy-resource-on-return",
+			[]Option[*pingableResource]{WithTestOnReturn[*pingableResource]()},
+			true,
+			1,
+			true,
+		},
+		{
+			"should-replace-unhealthy-resource-on-return",
+			[]Option[*pingableResource]{WithTestOnReturn[*pingableResource]()},
+			false,
+			1,
+			false,
+		},
+		{
+			"should-use-custom-health-check",
+			[]Option[*pingableResource]{
+				WithTestOnBorrow[*pingableResource](),
+				WithHealthCheck(func(ctx context.Context, r *pingableResource) error {
+					return errors.New("always broken")
+				}),
+			},
+			true,
+			0,
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pool, err := New(newPingFactory(), c.opts...)
+			require.NoError(t, err)
+			defer pool.Close()
+
+			first, err := pool.Get(context.Background())
+			require.NoError(t, err)
+			first.healthy.Store(c.healthy)
+			require.NoError(t, pool.Put(first))
+
+			next, err := pool.Get(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, c.expectedReused, next == first)
+			require.Equal(t, c.expectedPings, first.pings.Load())
+			require.Eventually(t, func() bool { return first.closed.Load() != c.expectedReused }, time.Second, time.Millisecond)
+		})
+	}
+}
+
+func TestPoolBorrowCheckCancelled(t *testing.T) {
+	pool, err := New(newPingFactory(), WithTestOnBorrow[*pingableResource](), WithHealthCheck(
+		func(ctx context.Context, r *pingableResource) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	r, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, pool.Put(r))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = pool.Get(ctx)
+	require.ErrorIs(t, err, ErrOutOfResources)
+
+	// The check did not fail on its own, the resource goes back to the pool.
+	require.False(t, r.closed.Load())
+	require.Equal(t, 1, idleCount(pool))
+}
+
+func TestPoolCheckIdle(t *testing.T) {
+	pool, err := New(newPingFactory(),
+		WithMinIdle[*pingableResource](2),
+		WithMaxSize[*pingableResource](2),
+		WithWaitForMinIdle[*pingableResource](time.Second),
+		WithIdleCheck[*pingableResource](time.Hour))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	pool.mu.Lock()
+	broken, healthy := pool.idle[0].v, pool.idle[1].v
+	pool.mu.Unlock()
+	broken.healthy.Store(false)
+
+	pool.checkIdle()
+
+	require.True(t, broken.closed.Load())
+	require.False(t, healthy.closed.Load())
+	require.Equal(t, int32(1), healthy.pings.Load())
+	// The filler replaces the broken resource.
+	require.Eventually(t, func() bool { return idleCount(pool) == 2 }, time.Second, time.Millisecond)
+}
+
+func TestPoolIdleCheckLoop(t *testing.T) {
+	pool, err := New(newPingFactory(), WithIdleCheck[*pingableResource](time.Millisecond))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	r, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	r.healthy.Store(false)
+	require.NoError(t, pool.Put(r))
+
+	require.Eventually(t, r.closed.Load, time.Second, time.Millisecond)
+}
+
+func TestPoolRejectsInvalidHealthCheckSettings(t *testing.T) {
+	cases := []struct {
+		name string
+		opt  Option[*pingableResource]
+	}{
+		{"should-reject-nil-health-check", WithHealthCheck[*pingableResource](nil)},
+		{"should-reject-zero-idle-check-interval", WithIdleCheck[*pingableResource](0)},
+		{"should-reject-zero-check-timeout", WithCheckTimeout[*pingableResource](0)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(newPingFactory(), c.opt)
+			require.Error(t, err)
+		})
+	}
+}
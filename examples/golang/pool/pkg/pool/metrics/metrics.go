@@ -0,0 +1,287 @@
+// Package metrics exports pool statistics and acquire latencies under expvar and in the
+// Prometheus text exposition format, using only the standard library.
+package metrics
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/pkg/errors"
+
+	"pool/pkg/pool"
+)
+
+// DefaultBuckets are the upper bounds of the acquire latency histogram unless WithBuckets is given.
+var DefaultBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2500 * time.Millisecond,
+	5 * time.Second, 10 * time.Second,
+}
+
+// DefaultExpvarPrefix is prepended to pool names published under expvar unless WithExpvarPrefix is given.
+const DefaultExpvarPrefix = "pool."
+
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Registry holds a Collector per named pool. It serves all of them in the Prometheus text format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]*Collector
+
+	buckets      []time.Duration
+	expvarPrefix string
+}
+
+type Option func(*Registry) error
+
+// WithBuckets sets the upper bounds of the acquire latency histogram, in ascending order.
+func WithBuckets(buckets ...time.Duration) Option {
+	return func(r *Registry) error {
+		if len(buckets) == 0 || buckets[0] <= 0 {
+			return errors.New("invalid histogram buckets")
+		}
+		for i := 1; i < len(buckets); i++ {
+			if buckets[i] <= buckets[i-1] {
+				return errors.New("histogram buckets must ascend")
+			}
+		}
+		r.buckets = slices.Clone(buckets)
+		return nil
+	}
+}
+
+// WithExpvarPrefix namespaces the pools published under expvar, an empty prefix disables expvar.
+func WithExpvarPrefix(prefix string) Option {
+	return func(r *Registry) error {
+		r.expvarPrefix = prefix
+		return nil
+	}
+}
+
+func NewRegistry(opts ...Option) (*Registry, error) {
+	r := &Registry{
+		collectors:   map[string]*Collector{},
+		buckets:      DefaultBuckets,
+		expvarPrefix: DefaultExpvarPrefix,
+	}
+	for _, o := range opts {
+		if err := o(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a pool under name with additional constant labels. Pass the Collector to
+// pool.WithObserver of the pool it describes, e.g. through the options of NewConnectionPool.
+// Names are published under expvar, which has no way to remove them again.
+func (r *Registry) Register(name string, labels map[string]string) (*Collector, error) {
+	if name == "" {
+		return nil, errors.New("empty pool name")
+	}
+	for label := range labels {
+		if !labelName.MatchString(label) || label == "pool" || label == "le" {
+			return nil, errors.Errorf("invalid label name %q", label)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collectors[name]; ok {
+		return nil, errors.Errorf("pool %q is already registered", name)
+	}
+	if r.expvarPrefix != "" && expvar.Get(r.expvarPrefix+name) != nil {
+		return nil, errors.Errorf("expvar %q is already published", r.expvarPrefix+name)
+	}
+
+	c := &Collector{
+		name:    name,
+		labels:  formatLabels(name, labels),
+		buckets: r.buckets,
+		counts:  make([]atomic.Uint64, len(r.buckets)),
+	}
+	r.collectors[name] = c
+	if r.expvarPrefix != "" {
+		expvar.Publish(r.expvarPrefix+name, expvar.Func(c.expvar))
+	}
+	return c, nil
+}
+
+// ServeHTTP writes the metrics of all pools in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+type metric struct {
+	name, kind, help string
+	value            func(pool.Stats) float64
+}
+
+var metrics = []metric{
+	{"pool_max_open", "gauge", "Maximum number of open resources.", func(s pool.Stats) float64 { return float64(s.MaxSize) }},
+	{"pool_open", "gauge", "Resources idle, in use or being created.", func(s pool.Stats) float64 { return float64(s.Open) }},
+	{"pool_in_use", "gauge", "Resources handed out, being created or being checked.", func(s pool.Stats) float64 { return float64(s.InUse) }},
+	{"pool_idle", "gauge", "Idle resources.", func(s pool.Stats) float64 { return float64(s.Idle) }},
+	{"pool_waits_total", "counter", "Get calls which had to wait for a resource.", func(s pool.Stats) float64 { return float64(s.WaitCount) }},
+	{"pool_wait_seconds_total", "counter", "Total time Get calls waited.", func(s pool.Stats) float64 { return s.WaitDuration.Seconds() }},
+	{"pool_timeouts_total", "counter", "Get calls which gave up waiting.", func(s pool.Stats) float64 { return float64(s.Timeouts) }},
+	{"pool_created_total", "counter", "Successful factory calls.", func(s pool.Stats) float64 { return float64(s.Created) }},
+	{"pool_factory_failures_total", "counter", "Failed factory calls.", func(s pool.Stats) float64 { return float64(s.FactoryFailures) }},
+	{"pool_refills_total", "counter", "Resources created in the background to keep the minimum idle.", func(s pool.Stats) float64 { return float64(s.Refills) }},
+	{"pool_invalid_closed_total", "counter", "Resources discarded as invalid or unhealthy.", func(s pool.Stats) float64 { return float64(s.InvalidClosed) }},
+	{"pool_expired_closed_total", "counter", "Resources discarded for max idle time or max lifetime.", func(s pool.Stats) float64 { return float64(s.ExpiredClosed) }},
+	{"pool_closed_total", "counter", "Resources closed by the pool.", func(s pool.Stats) float64 { return float64(s.Closed) }},
+}
+
+// WriteTo writes the metrics of all pools in the Prometheus text exposition format, pools ordered by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	collectors := make([]*Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name < collectors[j].name })
+
+	stats := make([]pool.Stats, len(collectors))
+	attached := make([]bool, len(collectors))
+	for i, c := range collectors {
+		stats[i], attached[i] = c.Stats()
+	}
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for i, c := range collectors {
+			if attached[i] {
+				fmt.Fprintf(&buf, "%s{%s} %s\n", m.name, c.labels, formatFloat(m.value(stats[i])))
+			}
+		}
+	}
+
+	const histogram = "pool_acquire_duration_seconds"
+	fmt.Fprintf(&buf, "# HELP %s Time successful Get calls took.\n# TYPE %s histogram\n", histogram, histogram)
+	for _, c := range collectors {
+		c.writeHistogram(&buf, histogram)
+	}
+	return buf.WriteTo(w)
+}
+
+// Collector exports the metrics of one pool, it implements pool.Observer.
+type Collector struct {
+	name    string
+	labels  string // Formatted, including the pool name.
+	buckets []time.Duration
+	mu      sync.Mutex // Guards statsFn.
+	statsFn func() pool.Stats
+
+	counts []atomic.Uint64 // Observations per bucket, not cumulative, the last bucket is +Inf.
+	inf    atomic.Uint64
+	sum    atomic.Duration
+	count  atomic.Uint64
+}
+
+var _ pool.Observer = &Collector{}
+
+func (c *Collector) Attach(stats func() pool.Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statsFn = stats
+}
+
+func (c *Collector) Acquired(d time.Duration) {
+	i, _ := slices.BinarySearch(c.buckets, d)
+	if i < len(c.counts) {
+		c.counts[i].Inc()
+	} else {
+		c.inf.Inc()
+	}
+	c.sum.Add(d)
+	c.count.Inc()
+}
+
+// Stats returns the statistics of the attached pool, false before a pool is attached.
+func (c *Collector) Stats() (pool.Stats, bool) {
+	c.mu.Lock()
+	statsFn := c.statsFn
+	c.mu.Unlock()
+	if statsFn == nil {
+		return pool.Stats{}, false
+	}
+	return statsFn(), true
+}
+
+func (c *Collector) writeHistogram(w io.Writer, name string) {
+	var cumulative uint64
+	for i, bound := range c.buckets {
+		cumulative += c.counts[i].Load()
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, c.labels, formatFloat(bound.Seconds()), cumulative)
+	}
+	cumulative += c.inf.Load()
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, c.labels, cumulative)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, c.labels, formatFloat(c.sum.Load().Seconds()))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, c.labels, c.count.Load())
+}
+
+// expvar is the JSON value published for the pool.
+func (c *Collector) expvar() any {
+	buckets := make(map[string]uint64, len(c.buckets)+1)
+	var cumulative uint64
+	for i, bound := range c.buckets {
+		cumulative += c.counts[i].Load()
+		buckets[formatFloat(bound.Seconds())] = cumulative
+	}
+	buckets["+Inf"] = cumulative + c.inf.Load()
+
+	value := map[string]any{
+		"acquire_duration_seconds": map[string]any{
+			"buckets": buckets,
+			"sum":     c.sum.Load().Seconds(),
+			"count":   c.count.Load(),
+		},
+	}
+	if stats, ok := c.Stats(); ok {
+		value["stats"] = stats
+	}
+	return value
+}
+
+// formatLabels renders the pool name and constant labels sorted by name.
+func formatLabels(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "pool=\"%s\"", escapeLabel(name))
+	for _, label := range names {
+		fmt.Fprintf(&sb, ",%s=\"%s\"", label, escapeLabel(labels[label]))
+	}
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,172 @@
+//go:build unit
+// +build unit
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"pool/pkg/pool"
+)
+
+type resource struct{}
+
+func newResource() (*resource, error) {
+	return &resource{}, nil
+}
+
+func TestRegistryWriteTo(t *testing.T) {
+	registry, err := NewRegistry(WithExpvarPrefix(""), WithBuckets(time.Millisecond, time.Second))
+	require.NoError(t, err)
+
+	collector, err := registry.Register("workers", map[string]string{"service": "publisher", "zone": `eu "west"`})
+	require.NoError(t, err)
+	p, err := pool.New(newResource, pool.WithMaxSize[*resource](3), pool.WithObserver[*resource](collector))
+	require.NoError(t, err)
+	defer p.Close()
+
+	v, err := p.Get(context.Background())
+	require.NoError(t, err)
+	_, err = p.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, p.Put(v))
+	collector.Acquired(500 * time.Millisecond)
+	collector.Acquired(time.Minute)
+
+	var buf bytes.Buffer
+	_, err = registry.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	labels := `pool="workers",service="publisher",zone="eu \"west\""`
+	for _, line := range []string{
+		"# TYPE pool_max_open gauge",
+		"pool_max_open{" + labels + "} 3",
+		"pool_open{" + labels + "} 2",
+		"pool_in_use{" + labels + "} 1",
+		"pool_idle{" + labels + "} 1",
+		"# TYPE pool_created_total counter",
+		"pool_created_total{" + labels + "} 2",
+		"# TYPE pool_acquire_duration_seconds histogram",
+		"pool_acquire_duration_seconds_bucket{" + labels + `,le="1"} 3`,
+		"pool_acquire_duration_seconds_bucket{" + labels + `,le="+Inf"} 4`,
+		"pool_acquire_duration_seconds_count{" + labels + "} 4",
+	} {
+		require.Contains(t, out, line+"\n")
+	}
+}
+
+func TestCollectorHistogram(t *testing.T) {
+	registry, err := NewRegistry(WithExpvarPrefix(""), WithBuckets(time.Millisecond, 10*time.Millisecond))
+	require.NoError(t, err)
+	collector, err := registry.Register("histogram", nil)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		observed time.Duration
+		expected string
+	}{
+		{"should-count-below-first-bound", 500 * time.Microsecond, `le="0.001"} 1`},
+		{"should-count-on-bound-inclusively", time.Millisecond, `le="0.001"} 2`},
+		{"should-count-cumulatively", 5 * time.Millisecond, `le="0.01"} 3`},
+		{"should-count-above-last-bound", time.Second, `le="+Inf"} 4`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			collector.Acquired(c.observed)
+			var buf bytes.Buffer
+			collector.writeHistogram(&buf, "latency")
+			require.Contains(t, buf.String(), `latency_bucket{pool="histogram",`+c.expected+"\n")
+		})
+	}
+}
+
+func TestRegistryExpvar(t *testing.T) {
+	// expvar names are process-wide, a fresh prefix keeps repeated test runs apart.
+	prefix := fmt.Sprintf("test.pool.%d.", time.Now().UnixNano())
+	registry, err := NewRegistry(WithExpvarPrefix(prefix))
+	require.NoError(t, err)
+	collector, err := registry.Register("expvar", nil)
+	require.NoError(t, err)
+	p, err := pool.New(newResource, pool.WithObserver[*resource](collector))
+	require.NoError(t, err)
+	defer p.Close()
+	_, err = p.Get(context.Background())
+	require.NoError(t, err)
+
+	var value struct {
+		Acquire struct {
+			Count uint64 `json:"count"`
+		} `json:"acquire_duration_seconds"`
+		Stats pool.Stats `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(prefix+"expvar").String()), &value))
+	require.Equal(t, uint64(1), value.Acquire.Count)
+	require.Equal(t, 1, value.Stats.InUse)
+
+	// expvar names are global, another registry cannot take them.
+	other, err := NewRegistry(WithExpvarPrefix(prefix))
+	require.NoError(t, err)
+	_, err = other.Register("expvar", nil)
+	require.Error(t, err)
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	registry, err := NewRegistry(WithExpvarPrefix(""))
+	require.NoError(t, err)
+	_, err = registry.Register("detached", nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	// Without an attached pool only the histogram is known.
+	require.NotContains(t, rec.Body.String(), `pool_open{pool="detached"}`)
+	require.Contains(t, rec.Body.String(), `pool_acquire_duration_seconds_count{pool="detached"} 0`)
+}
+
+func TestRegistryRejectsInvalidSettings(t *testing.T) {
+	_, err := NewRegistry(WithBuckets())
+	require.Error(t, err)
+	_, err = NewRegistry(WithBuckets(time.Second, time.Millisecond))
+	require.Error(t, err)
+	_, err = NewRegistry(WithBuckets(0))
+	require.Error(t, err)
+
+	registry, err := NewRegistry(WithExpvarPrefix(""))
+	require.NoError(t, err)
+	cases := []struct {
+		name   string
+		pool   string
+		labels map[string]string
+	}{
+		{"should-reject-empty-name", "", nil},
+		{"should-reject-invalid-label-name", "invalid", map[string]string{"zone-name": "eu"}},
+		{"should-reject-reserved-label-name", "reserved", map[string]string{"le": "1"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := registry.Register(c.pool, c.labels)
+			require.Error(t, err)
+		})
+	}
+
+	_, err = registry.Register("twice", nil)
+	require.NoError(t, err)
+	_, err = registry.Register("twice", nil)
+	require.Error(t, err)
+}
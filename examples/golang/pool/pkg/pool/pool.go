@@ -1,23 +1,28 @@
 package pool
 
 import (
+	"container/list"
 	"context"
+	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
-	"go.uber.org/atomic"
-
 	"go.uber.org/zap"
 
 	"github.com/pkg/errors"
 )
 
+// DefaultMaxSize limits the number of open resources unless WithMaxSize is given.
+const DefaultMaxSize = 10
+
 var (
 	ErrPoolClosed          = errors.New("pool is closed")
 	ErrOutOfResources      = errors.New("no resources were found in time")
 	ErrAllResourcesInvalid = errors.New("all resources are in an invalid state")
 )
 
+// Poolable resources report their own validity, the pool uses Valid unless a validator is set with WithValidator.
 type Poolable interface {
 	// Valid returns false, the pool will try to replace the invalid instance and refill automatically
 	Valid() bool
@@ -26,42 +31,129 @@ type Poolable interface {
 	Validate()
 }
 
-type factory func() (Poolable, error)
-
-type Pool struct {
-	mu sync.RWMutex
-
-	ch  chan Poolable
-	f   factory
-	log *zap.Logger
+// Factory creates a new resource for the pool.
+type Factory[T any] func() (T, error)
+
+// Validator reports whether a resource may still be handed out.
+type Validator[T any] func(T) bool
+
+// Pool hands out resources created on demand, up to MaxSize open at a time.
+// A background filler keeps at least MinIdle of them idle, so Get rarely waits for the factory.
+type Pool[T any] struct {
+	mu sync.Mutex
+
+	idle []idleResource[T]
+	open int // Idle, in use and being created.
+	// created holds the creation time of open resources for MaxLifetime, keyed by the resource itself.
+	created map[any]time.Time
+	// waiters are the Get calls waiting for a resource or a slot, served directly in waitOrder.
+	waiters   *list.List
+	waitOrder WaitOrder
+
+	f     Factory[T]
+	valid Validator[T]
+	log   *zap.Logger
+
+	minIdle     int
+	maxSize     int
+	startupWait time.Duration
+	maxIdleTime time.Duration
+	maxLifetime time.Duration
+	now         func() time.Time
+
+	check        HealthCheck[T]
+	testOnBorrow bool
+	testOnReturn bool
+	idleCheck    time.Duration
+	checkTimeout time.Duration
 
 	closed        bool
-	refillTimeout time.Duration
+	refillTimeout time.Duration // Delay before the filler retries a failed factory call.
+
+	stats    counters
+	observer Observer
 
-	healthy *atomic.Int32
+	fill   chan struct{}
+	filled chan struct{} // Notified whenever the filler adds a resource.
+	done   chan struct{}
+	wg     sync.WaitGroup
 }
 
-type Option func(*Pool) error
+type Option[T any] func(*Pool[T]) error
 
-func WithLog(log *zap.Logger) Option {
-	return func(pool *Pool) error {
+func WithLog[T any](log *zap.Logger) Option[T] {
+	return func(pool *Pool[T]) error {
 		pool.log = log
 		return nil
 	}
 }
 
-func New(capacity int, factory factory, opts ...Option) (*Pool, error) {
-	if capacity <= 0 {
-		return nil, errors.New("invalid capacity settings")
+// WithValidator decides about the validity of resources, so plain structs can be pooled without implementing Poolable.
+func WithValidator[T any](valid Validator[T]) Option[T] {
+	return func(pool *Pool[T]) error {
+		if valid == nil {
+			return errors.New("invalid validator settings")
+		}
+		pool.valid = valid
+		return nil
 	}
+}
+
+// WithMinIdle keeps at least n resources idle, as far as MaxSize allows. The default is 0.
+func WithMinIdle[T any](n int) Option[T] {
+	return func(pool *Pool[T]) error {
+		pool.minIdle = n
+		return nil
+	}
+}
+
+// WithMaxSize limits the number of open resources, idle and in use, to n. The default is DefaultMaxSize.
+func WithMaxSize[T any](n int) Option[T] {
+	return func(pool *Pool[T]) error {
+		pool.maxSize = n
+		return nil
+	}
+}
 
-	pool := &Pool{
-		ch:            make(chan Poolable, capacity),
+// WithWaitForMinIdle makes New block until MinIdle resources are created, failing after timeout.
+// By default New returns at once and the pool warms up in the background, retrying failed factory calls.
+func WithWaitForMinIdle[T any](timeout time.Duration) Option[T] {
+	return func(pool *Pool[T]) error {
+		if timeout <= 0 {
+			return errors.New("invalid startup timeout settings")
+		}
+		pool.startupWait = timeout
+		return nil
+	}
+}
+
+// WithRefillTimeout sets the delay before a failed factory call of the background filler is retried, one second by default.
+func WithRefillTimeout[T any](d time.Duration) Option[T] {
+	return func(pool *Pool[T]) error {
+		if d <= 0 {
+			return errors.New("invalid refill timeout settings")
+		}
+		pool.refillTimeout = d
+		return nil
+	}
+}
+
+func New[T any](factory Factory[T], opts ...Option[T]) (*Pool[T], error) {
+	pool := &Pool[T]{
+		waiters:       list.New(),
+		created:       map[any]time.Time{},
 		f:             factory,
+		valid:         poolableValid[T],
+		log:           zap.NewNop(),
+		maxSize:       DefaultMaxSize,
+		now:           time.Now,
+		check:         pingResource[T],
+		checkTimeout:  DefaultCheckTimeout,
 		closed:        false,
 		refillTimeout: time.Second,
-		log:           zap.NewNop(),
-		healthy:       atomic.NewInt32(int32(capacity)),
+		fill:          make(chan struct{}, 1),
+		filled:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
 	}
 
 	for _, o := range opts {
@@ -69,47 +161,178 @@ func New(capacity int, factory factory, opts ...Option) (*Pool, error) {
 			return nil, err
 		}
 	}
+	if pool.maxSize <= 0 || pool.minIdle < 0 || pool.minIdle > pool.maxSize {
+		return nil, errors.New("invalid capacity settings")
+	}
+	if pool.observer != nil {
+		pool.observer.Attach(pool.Stats)
+	}
+
+	go pool.fillLoop()
+	pool.requestFill()
+
+	if interval := pool.reapInterval(); interval > 0 {
+		pool.wg.Add(1)
+		go pool.reapLoop(interval)
+	}
+	if pool.idleCheck > 0 {
+		pool.wg.Add(1)
+		go pool.idleCheckLoop()
+	}
 
-	// create initial poolables, if something goes wrong, just close the Pool and error out.
-	for i := 0; i < capacity; i++ {
-		v, err := factory()
-		if err != nil {
+	if pool.startupWait > 0 {
+		if err := pool.waitForMinIdle(pool.startupWait); err != nil {
 			pool.Close()
-			return nil, errors.Wrap(err, "factory is not able to fill the pool")
+			return nil, err
 		}
-		pool.ch <- v
 	}
-
 	return pool, nil
 }
 
-func (p *Pool) Get(ctx context.Context) (Poolable, error) {
-	if p.healthy.Load() == 0 {
-		return nil, ErrAllResourcesInvalid
+// poolableValid is the default validator, resources not implementing Poolable are always valid.
+func poolableValid[T any](v T) bool {
+	if p, ok := any(v).(Poolable); ok {
+		return p.Valid()
+	}
+	return true
+}
+
+// Get returns an idle resource, creates one if fewer than MaxSize are open or waits, in WaitOrder, for one
+// to be returned or a slot to free up.
+// Invalid, expired and, with WithTestOnBorrow, unhealthy idle resources are discarded on the way.
+func (p *Pool[T]) Get(ctx context.Context) (T, error) {
+	if p.observer == nil {
+		return p.get(ctx)
 	}
 
-	invalid := 0
+	start := time.Now()
+	v, err := p.get(ctx)
+	if err == nil {
+		p.observer.Acquired(time.Since(start))
+	}
+	return v, err
+}
+
+func (p *Pool[T]) get(ctx context.Context) (T, error) {
+	var zero T
+	var waitStart time.Time
 	defer func() {
-		go p.refill(invalid)
+		if !waitStart.IsZero() {
+			p.stats.waitDuration.Add(time.Since(waitStart))
+		}
 	}()
 
+	factoryFailed := false
 	for {
-		select {
-		case <-ctx.Done():
-			return nil, ErrOutOfResources
-		case v, ok := <-p.ch:
-			if !ok {
-				return nil, ErrPoolClosed
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return zero, ErrPoolClosed
+		}
+
+		r, ok := p.takeIdle()
+		slot := false
+		if !ok && !factoryFailed && p.open < p.maxSize {
+			p.open++
+			slot = true
+		}
+		var w *waiter[T]
+		if !ok && !slot {
+			w = p.enqueue()
+		}
+		p.mu.Unlock()
+
+		if w != nil {
+			if waitStart.IsZero() {
+				waitStart = time.Now()
+				p.stats.waitCount.Inc()
 			}
-			if !v.Valid() {
-				invalid++
-				continue
+			h, err := p.wait(ctx, w)
+			if errors.Is(err, ErrOutOfResources) {
+				p.stats.timeouts.Inc()
+			}
+			if err != nil {
+				return zero, err
+			}
+			r, ok, slot = h.r, !h.slot, h.slot
+		}
+
+		if ok {
+			p.requestFill()
+			if !p.testOnBorrow {
+				return r.v, nil
+			}
+
+			err := p.check(ctx, r.v)
+			if err == nil {
+				return r.v, nil
+			}
+			if ctx.Err() != nil {
+				// The caller gave up, the resource is not to blame.
+				p.restore(r)
+				p.stats.timeouts.Inc()
+				return zero, ErrOutOfResources
 			}
+			p.drop(r.v, err)
+			continue
+		}
+
+		v, err := p.f()
+		p.stats.factoryCall(err)
+		p.mu.Lock()
+		if err == nil {
+			p.track(v)
+			p.mu.Unlock()
 			return v, nil
 		}
+		p.release()
+		open := p.open
+		p.mu.Unlock()
+		if open == 0 {
+			return zero, fmt.Errorf("%w: %v", ErrAllResourcesInvalid, err)
+		}
+		// Other resources are open, wait for one of them instead of hammering the factory.
+		p.log.Debug("unable to create resource", zap.Error(err), zap.Bool("retry", true))
+		factoryFailed = true
 	}
 }
 
+// takeIdle pops the oldest usable idle resource, discarding invalid and expired ones. It requires p.mu.
+func (p *Pool[T]) takeIdle() (idleResource[T], bool) {
+	now := p.now()
+	for len(p.idle) > 0 {
+		var r idleResource[T]
+		if p.waitOrder == WaitLIFO {
+			last := len(p.idle) - 1
+			r = p.idle[last]
+			p.idle[last] = idleResource[T]{}
+			p.idle = p.idle[:last]
+		} else {
+			r = p.idle[0]
+			p.idle[0] = idleResource[T]{}
+			p.idle = p.idle[1:]
+		}
+
+		if !p.valid(r.v) {
+			p.stats.invalidClosed.Inc()
+		} else if p.expired(r, now) {
+			p.stats.expiredClosed.Inc()
+		} else {
+			return r, true
+		}
+		p.destroy(r.v)
+		go p.discard(r.v)
+	}
+	return idleResource[T]{}, false
+}
+
+// restore returns a resource taken by takeIdle unused, keeping its idle time.
+func (p *Pool[T]) restore(r idleResource[T]) {
+	p.mu.Lock()
+	p.putIdle(r)
+	p.mu.Unlock()
+}
+
 type errCloser interface {
 	Close() error
 }
@@ -118,72 +341,196 @@ type nilCloser interface {
 	Close()
 }
 
-func (p *Pool) Put(v Poolable) error {
-	if v == nil {
+// Put returns a resource obtained from Get. Invalid resources, those past MaxLifetime and, with
+// WithTestOnReturn, unhealthy ones are closed and replaced as needed.
+func (p *Pool[T]) Put(v T) error {
+	if isNil(v) {
 		return errors.New("rejecting <nil> poolable")
 	}
 
-	// lock the mutex because we are sending to p.ch
-	// without the lock one could call close; close ch and the send to p.ch would panic
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	if p.testOnReturn {
+		if err := p.checkWithTimeout(v); err != nil {
+			p.drop(v, err)
+			return nil
+		}
+	}
 
+	p.mu.Lock()
 	if p.closed {
 		// pool is closed, try to close passed poolable
-		switch t := v.(type) {
-		case errCloser:
-			return t.Close()
-		case nilCloser:
-			t.Close()
-			return nil
-		default:
-			return nil
+		p.destroy(v)
+		p.mu.Unlock()
+		p.stats.closed.Inc()
+		return closeResource(v)
+	}
+
+	now := p.now()
+	if !p.valid(v) || p.pastLifetime(v, now) {
+		if !p.valid(v) {
+			p.stats.invalidClosed.Inc()
+		} else {
+			p.stats.expiredClosed.Inc()
 		}
+		p.destroy(v)
+		p.mu.Unlock()
+		p.discard(v)
+		p.requestFill()
+		return nil
 	}
 
-	p.ch <- v
+	p.putIdle(idleResource[T]{v: v, since: now})
+	p.mu.Unlock()
 	return nil
 }
 
-func (p *Pool) Close() {
+// Close closes the idle resources and stops the background work. A factory call of the filler in progress
+// is not waited for, the resource it returns is closed.
+func (p *Pool[T]) Close() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	if p.closed {
+		p.mu.Unlock()
 		return
 	}
 	p.closed = true
 
-	close(p.ch)
-
-	for v := range p.ch {
-		switch t := v.(type) {
-		case errCloser:
-			if err := t.Close(); err != nil {
-				p.log.Warn("error closing poolable", zap.Error(err))
-			}
-		case nilCloser:
-			t.Close()
+	idle := p.idle
+	p.idle = nil
+	p.open -= len(idle)
+	for _, r := range idle {
+		p.untrack(r.v)
+	}
+	close(p.done)
+	p.closeWaiters()
+	p.mu.Unlock()
+
+	for _, r := range idle {
+		p.stats.closed.Inc()
+		if err := closeResource(r.v); err != nil {
+			p.log.Warn("error closing poolable", zap.Error(err))
 		}
 	}
+	p.wg.Wait()
 }
 
-func (p *Pool) refill(invalid int) {
-	p.healthy.Sub(int32(invalid))
-	for j := 0; j < invalid; time.Sleep(p.refillTimeout) {
-		if p.closed {
+// destroy frees the slot of a resource about to be closed. It requires p.mu.
+func (p *Pool[T]) destroy(v T) {
+	p.untrack(v)
+	p.release()
+}
+
+func (p *Pool[T]) discard(v T) {
+	p.stats.closed.Inc()
+	if err := closeResource(v); err != nil {
+		p.log.Warn("error closing discarded poolable", zap.Error(err))
+	}
+}
+
+func (p *Pool[T]) requestFill() {
+	select {
+	case p.fill <- struct{}{}:
+	default:
+	}
+}
+
+// fillLoop creates resources until MinIdle are idle, whenever requested.
+// Close does not wait for it, as the factory may block; it discards resources created after Close.
+func (p *Pool[T]) fillLoop() {
+	for {
+		select {
+		case <-p.done:
 			return
+		case <-p.fill:
 		}
-		v, err := p.f()
-		if err != nil {
-			p.log.Debug("unable to refill pool", zap.Error(err), zap.Bool("retry", true))
-			continue
+
+		for p.reserveIdle() {
+			v, err := p.f()
+			p.stats.factoryCall(err)
+			if err != nil {
+				p.mu.Lock()
+				p.release()
+				p.mu.Unlock()
+
+				p.log.Debug("unable to refill pool", zap.Error(err), zap.Bool("retry", true))
+				select {
+				case <-p.done:
+					return
+				case <-time.After(p.refillTimeout):
+				}
+				continue
+			}
+
+			p.mu.Lock()
+			if p.closed {
+				p.open--
+				p.mu.Unlock()
+				p.discard(v)
+				return
+			}
+			p.stats.refills.Inc()
+			p.track(v)
+			p.putIdle(idleResource[T]{v: v, since: p.now()})
+			p.mu.Unlock()
+
+			select {
+			case p.filled <- struct{}{}:
+			default:
+			}
 		}
-		if err := p.Put(v); err != nil {
-			p.log.Debug("unable to refill pool", zap.Error(err), zap.Bool("retry", true))
-			continue
+	}
+}
+
+// reserveIdle takes a slot for a new idle resource if fewer than MinIdle are idle.
+func (p *Pool[T]) reserveIdle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || len(p.idle) >= p.minIdle || p.open >= p.maxSize {
+		return false
+	}
+	p.open++
+	return true
+}
+
+func (p *Pool[T]) waitForMinIdle(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		p.mu.Lock()
+		idle := len(p.idle)
+		p.mu.Unlock()
+		if idle >= p.minIdle {
+			return nil
+		}
+
+		select {
+		case <-timer.C:
+			return errors.Errorf("factory is not able to fill the pool: %d of %d resources created", idle, p.minIdle)
+		case <-p.filled:
 		}
-		j++
-		p.healthy.Add(1)
+	}
+}
+
+func closeResource[T any](v T) error {
+	switch t := any(v).(type) {
+	case errCloser:
+		return t.Close()
+	case nilCloser:
+		t.Close()
+	}
+	return nil
+}
+
+// isNil reports nil interfaces as well as nil pointers, maps, channels, funcs and slices.
+func isNil[T any](v T) bool {
+	value := reflect.ValueOf(any(v))
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return value.IsNil()
+	default:
+		return false
 	}
 }
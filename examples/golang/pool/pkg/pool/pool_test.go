@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 
 	"github.com/pkg/errors"
@@ -23,109 +24,242 @@ func (mp *mockPoolable) Close() error {
 	return mp.errClose
 }
 
+func validFactory() (*mockPoolable, error) {
+	return &mockPoolable{NewAtomicValidatable(true), nil}, nil
+}
+
+// flakyFactory fails the first failures calls.
+func flakyFactory(failures int32) (Factory[*mockPoolable], *atomic.Int32) {
+	calls := atomic.NewInt32(0)
+	return func() (*mockPoolable, error) {
+		if calls.Inc() <= failures {
+			return nil, errors.New("boom")
+		}
+		return validFactory()
+	}, calls
+}
+
+func idleCount[T any](p *Pool[T]) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle)
+}
+
 func TestNewPoolSuccess(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
-	}
-	_, err := New(1, factory, WithLog(zap.NewNop()))
+	_, err := New(validFactory, WithLog[*mockPoolable](zap.NewNop()))
 	require.NoError(t, err)
 }
 
+func TestNewPoolToleratesFailureFactory(t *testing.T) {
+	factory, _ := flakyFactory(2)
+	pool, err := New(factory, WithMinIdle[*mockPoolable](1), WithRefillTimeout[*mockPoolable](time.Millisecond))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.Eventually(t, func() bool { return idleCount(pool) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestNewPoolWaitsForMinIdle(t *testing.T) {
+	factory, calls := flakyFactory(2)
+	pool, err := New(factory,
+		WithMinIdle[*mockPoolable](3),
+		WithRefillTimeout[*mockPoolable](time.Millisecond),
+		WithWaitForMinIdle[*mockPoolable](time.Second))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.Equal(t, 3, idleCount(pool))
+	require.Equal(t, int32(5), calls.Load())
+}
+
 func TestNewPoolFailureFactory(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return nil, errors.New("boom")
+	factory, _ := flakyFactory(1000)
+	_, err := New(factory,
+		WithMinIdle[*mockPoolable](1),
+		WithRefillTimeout[*mockPoolable](time.Millisecond),
+		WithWaitForMinIdle[*mockPoolable](10*time.Millisecond))
+	require.Error(t, err)
+}
+
+type closeRecorder struct {
+	closed atomic.Bool
+}
+
+func (cr *closeRecorder) Close() error {
+	cr.closed.Store(true)
+	return nil
+}
+
+func TestNewPoolFailureDoesNotWaitForFactory(t *testing.T) {
+	created := make(chan *closeRecorder, 1)
+	factory := func() (*closeRecorder, error) {
+		time.Sleep(time.Second)
+		v := &closeRecorder{}
+		created <- v
+		return v, nil
 	}
-	_, err := New(1, factory)
+
+	start := time.Now()
+	_, err := New(factory,
+		WithMinIdle[*closeRecorder](1),
+		WithWaitForMinIdle[*closeRecorder](50*time.Millisecond))
 	require.Error(t, err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// The resource of the late factory call is closed, not kept.
+	v := <-created
+	require.Eventually(t, v.closed.Load, time.Second, time.Millisecond)
 }
 
 func TestNewPoolFailureCapSettings(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return nil, errors.New("boom")
-	}
-	_, err := New(0, factory)
+	_, err := New(validFactory, WithMaxSize[*mockPoolable](0))
+	require.Error(t, err)
+
+	_, err = New(validFactory, WithMaxSize[*mockPoolable](1), WithMinIdle[*mockPoolable](2))
 	require.Error(t, err)
 }
 
 func TestPoolGetErrClosed(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
-	}
-	pool, err := New(1, factory)
+	pool, err := New(validFactory)
 	require.NoError(t, err)
 	pool.Close()
 	_, err = pool.Get(context.Background())
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrPoolClosed)
 }
 
 func TestPoolClosedPutCloses(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
-	}
-	pool, err := New(1, factory)
+	pool, err := New(validFactory)
 	require.NoError(t, err)
 	pool.Close()
 	require.Error(t, pool.Put(&mockPoolable{NewAtomicValidatable(true), errors.New("boom")}))
 }
 
 func TestPoolPutNilError(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
-	}
-	pool, err := New(1, factory)
+	pool, err := New(validFactory)
 	require.NoError(t, err)
 	pool.Close()
 	require.Error(t, pool.Put(nil))
 }
 
-func TestPoolGetSuccessFromCache(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
+type plainResource struct {
+	id      int
+	expired bool
+}
+
+func TestPoolPlainStructWithValidator(t *testing.T) {
+	created := 0
+	factory := func() (plainResource, error) {
+		created++
+		return plainResource{id: created}, nil
 	}
-	pool, err := New(1, factory)
+	pool, err := New(factory, WithMaxSize[plainResource](1), WithValidator(func(r plainResource) bool {
+		return !r.expired
+	}))
 	require.NoError(t, err)
-	_, err = pool.Get(context.Background())
+	defer pool.Close()
+
+	r, err := pool.Get(context.Background())
 	require.NoError(t, err)
-	require.Equal(t, 0, len(pool.ch))
+	require.Equal(t, 1, r.id)
+
+	r.expired = true
+	require.NoError(t, pool.Put(r))
+
+	r, err = pool.Get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, r.id)
 }
 
-func TestPoolRefill(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
+func TestPoolRejectsNilValidator(t *testing.T) {
+	factory := func() (plainResource, error) {
+		return plainResource{}, nil
 	}
-	pool, err := New(1, factory)
+	_, err := New(factory, WithValidator[plainResource](nil))
+	require.Error(t, err)
+}
+
+func TestPoolPutNilPointer(t *testing.T) {
+	pool, err := New(validFactory)
 	require.NoError(t, err)
+	defer pool.Close()
+	require.Error(t, pool.Put((*mockPoolable)(nil)))
+}
 
-	pool.ch = make(chan Poolable, 1)
-	inv := &mockPoolable{NewAtomicValidatable(false), nil}
-	pool.ch <- inv
+func TestPoolGetCreatesLazily(t *testing.T) {
+	factory, calls := flakyFactory(0)
+	pool, err := New(factory, WithMaxSize[*mockPoolable](2))
+	require.NoError(t, err)
+	defer pool.Close()
+	require.Equal(t, int32(0), calls.Load())
 
-	pool.refillTimeout = time.Millisecond
+	first, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	_, err = pool.Get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), calls.Load())
 
+	// MaxSize is reached, the next Get waits until a resource is returned.
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 	defer cancel()
-
 	_, err = pool.Get(ctx)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrOutOfResources)
 
-	v := <-pool.ch
+	errs := make(chan error, 1)
+	go func() {
+		time.Sleep(time.Millisecond)
+		errs <- pool.Put(first)
+	}()
+	v, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, <-errs)
+	require.Same(t, first, v)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestPoolGetFailureFactory(t *testing.T) {
+	factory, _ := flakyFactory(1)
+	pool, err := New(factory)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Get(context.Background())
+	require.ErrorIs(t, err, ErrAllResourcesInvalid)
+
+	_, err = pool.Get(context.Background())
+	require.NoError(t, err)
+}
+
+func TestPoolRefill(t *testing.T) {
+	pool, err := New(validFactory,
+		WithMinIdle[*mockPoolable](1),
+		WithMaxSize[*mockPoolable](1),
+		WithWaitForMinIdle[*mockPoolable](time.Second))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	v, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	v.Invalidate()
+	require.NoError(t, pool.Put(v))
+
+	// The invalid resource freed its slot, the filler replaces it.
+	require.Eventually(t, func() bool { return idleCount(pool) == 1 }, time.Second, time.Millisecond)
+	v, err = pool.Get(context.Background())
+	require.NoError(t, err)
 	require.True(t, v.Valid())
 }
 
 func TestPoolCloseError(t *testing.T) {
-	factory := func() (Poolable, error) {
+	factory := func() (*mockPoolable, error) {
 		return &mockPoolable{NewAtomicValidatable(true), errors.New("boom")}, nil
 	}
-	pool, err := New(1, factory)
+	pool, err := New(factory, WithMinIdle[*mockPoolable](1), WithWaitForMinIdle[*mockPoolable](time.Second))
 	require.NoError(t, err)
 	pool.Close()
 }
 
 func TestPoolParallelClose(t *testing.T) {
-	factory := func() (Poolable, error) {
-		return &mockPoolable{NewAtomicValidatable(true), nil}, nil
-	}
-	pool, err := New(10, factory)
+	pool, err := New(validFactory, WithMinIdle[*mockPoolable](10), WithWaitForMinIdle[*mockPoolable](time.Second))
 	require.NoError(t, err)
 	for i := 0; i < 100; i++ {
 		go pool.Close()
@@ -0,0 +1,93 @@
+package pool
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+)
+
+// Stats is a snapshot of the pool, similar to sql.DBStats. Counters accumulate since New.
+type Stats struct {
+	MaxSize int // Maximum number of open resources.
+	Open    int // Resources idle, in use or being created.
+	InUse   int // Resources handed out, being created or being checked.
+	Idle    int
+
+	WaitCount    int64         // Get calls which had to wait for a resource.
+	WaitDuration time.Duration // Total time Get calls waited.
+	Timeouts     int64         // Get calls which gave up with ErrOutOfResources.
+
+	Created         int64 // Successful factory calls.
+	FactoryFailures int64 // Failed factory calls.
+	Refills         int64 // Resources created in the background to keep MinIdle, included in Created.
+	InvalidClosed   int64 // Resources discarded as invalid or unhealthy.
+	ExpiredClosed   int64 // Resources discarded for MaxIdleTime or MaxLifetime.
+	Closed          int64 // Resources closed by the pool for any reason.
+}
+
+// Observer follows a pool, e.g. to export its metrics.
+type Observer interface {
+	// Attach is called once by New with the pool's Stats method.
+	Attach(stats func() Stats)
+	// Acquired is called by every successful Get with the time it took.
+	Acquired(d time.Duration)
+}
+
+// WithObserver reports the pool to o.
+func WithObserver[T any](o Observer) Option[T] {
+	return func(pool *Pool[T]) error {
+		if o == nil {
+			return errors.New("invalid observer settings")
+		}
+		pool.observer = o
+		return nil
+	}
+}
+
+// counters are updated atomically, so the hot path takes no lock for them.
+type counters struct {
+	waitCount       atomic.Int64
+	waitDuration    atomic.Duration
+	timeouts        atomic.Int64
+	created         atomic.Int64
+	factoryFailures atomic.Int64
+	refills         atomic.Int64
+	invalidClosed   atomic.Int64
+	expiredClosed   atomic.Int64
+	closed          atomic.Int64
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool[T]) Stats() Stats {
+	p.mu.Lock()
+	open, idle := p.open, len(p.idle)
+	p.mu.Unlock()
+
+	return Stats{
+		MaxSize: p.maxSize,
+		Open:    open,
+		InUse:   open - idle,
+		Idle:    idle,
+
+		WaitCount:    p.stats.waitCount.Load(),
+		WaitDuration: p.stats.waitDuration.Load(),
+		Timeouts:     p.stats.timeouts.Load(),
+
+		Created:         p.stats.created.Load(),
+		FactoryFailures: p.stats.factoryFailures.Load(),
+		Refills:         p.stats.refills.Load(),
+		InvalidClosed:   p.stats.invalidClosed.Load(),
+		ExpiredClosed:   p.stats.expiredClosed.Load(),
+		Closed:          p.stats.closed.Load(),
+	}
+}
+
+// factoryCall counts the outcome of a factory call.
+func (c *counters) factoryCall(err error) {
+	if err != nil {
+		c.factoryFailures.Inc()
+	} else {
+		c.created.Inc()
+	}
+}
@@ -0,0 +1,118 @@
+//go:build unit
+// +build unit
+
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolStats(t *testing.T) {
+	clock := atomic.NewTime(time.Date(2024, 6, 25, 12, 0, 0, 0, time.UTC))
+	factory, _ := flakyFactory(1)
+	pool, err := New(factory,
+		WithMaxSize[*mockPoolable](2),
+		WithMaxIdleTime[*mockPoolable](time.Minute),
+		WithClock[*mockPoolable](clock.Load))
+	require.NoError(t, err)
+
+	// The first factory call fails.
+	_, err = pool.Get(context.Background())
+	require.ErrorIs(t, err, ErrAllResourcesInvalid)
+
+	first, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	second, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Stats{MaxSize: 2, Open: 2, InUse: 2, Created: 2, FactoryFailures: 1}, pool.Stats())
+
+	// MaxSize is reached, Get times out.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = pool.Get(ctx)
+	require.ErrorIs(t, err, ErrOutOfResources)
+
+	first.Invalidate()
+	require.NoError(t, pool.Put(first))
+	require.NoError(t, pool.Put(second))
+	clock.Store(clock.Load().Add(time.Minute))
+	_, err = pool.Get(context.Background())
+	require.NoError(t, err)
+
+	pool.Close()
+	stats := pool.Stats()
+	require.Equal(t, 1, stats.Open)
+	require.Equal(t, 1, stats.InUse)
+	require.Equal(t, 0, stats.Idle)
+	require.Equal(t, int64(1), stats.WaitCount)
+	require.GreaterOrEqual(t, stats.WaitDuration, time.Millisecond)
+	require.Equal(t, int64(1), stats.Timeouts)
+	require.Equal(t, int64(3), stats.Created)
+	require.Equal(t, int64(1), stats.InvalidClosed)
+	require.Equal(t, int64(1), stats.ExpiredClosed)
+	require.Eventually(t, func() bool { return pool.Stats().Closed == 2 }, time.Second, time.Millisecond)
+}
+
+func TestPoolStatsRefills(t *testing.T) {
+	pool, err := New(validFactory, WithMinIdle[*mockPoolable](3), WithWaitForMinIdle[*mockPoolable](time.Second))
+	require.NoError(t, err)
+	pool.Close()
+
+	stats := pool.Stats()
+	require.Equal(t, int64(3), stats.Refills)
+	require.Equal(t, int64(3), stats.Created)
+	require.Equal(t, int64(3), stats.Closed)
+	require.Equal(t, 0, stats.Open)
+}
+
+func TestPoolStatsPutAfterClose(t *testing.T) {
+	pool, err := New(validFactory)
+	require.NoError(t, err)
+
+	v, err := pool.Get(context.Background())
+	require.NoError(t, err)
+	pool.Close()
+	require.NoError(t, pool.Put(v))
+
+	stats := pool.Stats()
+	require.Equal(t, 0, stats.Open)
+	require.Equal(t, 0, stats.InUse)
+	require.Equal(t, int64(1), stats.Closed)
+}
+
+func TestPoolStatsConcurrentWaits(t *testing.T) {
+	pool, err := New(validFactory, WithMaxSize[*mockPoolable](1))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	held, err := pool.Get(context.Background())
+	require.NoError(t, err)
+
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			v, err := pool.Get(context.Background())
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- pool.Put(v)
+		}()
+	}
+	require.Eventually(t, func() bool { return pool.Stats().WaitCount == 4 }, time.Second, time.Millisecond)
+	require.NoError(t, pool.Put(held))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	stats := pool.Stats()
+	require.Equal(t, int64(4), stats.WaitCount)
+	require.Equal(t, int64(1), stats.Created)
+	require.Equal(t, 1, stats.Idle)
+}
@@ -0,0 +1,128 @@
+package pool
+
+import (
+	"container/list"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// WaitOrder decides which waiting Get is served first when a resource is returned or a slot frees up,
+// and which idle resource Get takes.
+type WaitOrder int
+
+const (
+	// WaitFIFO serves the longest waiting caller first, so nobody starves under contention,
+	// and takes the longest idle resource, spreading the use over all of them.
+	WaitFIFO WaitOrder = iota
+	// WaitLIFO serves the most recent caller first and takes the most recently returned resource.
+	// Use concentrates on few resources, so the surplus ones reach MaxIdleTime and are closed;
+	// under contention the callers waiting longest may starve.
+	WaitLIFO
+)
+
+// WithWaitOrder sets the order waiting Get calls are served in, WaitFIFO by default.
+func WithWaitOrder[T any](order WaitOrder) Option[T] {
+	return func(pool *Pool[T]) error {
+		if order != WaitFIFO && order != WaitLIFO {
+			return errors.New("invalid wait order settings")
+		}
+		pool.waitOrder = order
+		return nil
+	}
+}
+
+// handoff is what a waiter is served with: a resource, a slot to create one or the news that the pool closed.
+type handoff[T any] struct {
+	r      idleResource[T]
+	slot   bool
+	closed bool
+}
+
+type waiter[T any] struct {
+	ch   chan handoff[T] // Buffered, each waiter is served exactly once.
+	elem *list.Element
+}
+
+// enqueue registers a waiting Get. It requires p.mu.
+func (p *Pool[T]) enqueue() *waiter[T] {
+	w := &waiter[T]{ch: make(chan handoff[T], 1)}
+	w.elem = p.waiters.PushBack(w)
+	return w
+}
+
+// serve hands h to the next waiter in wait order, false if nobody waits. It requires p.mu.
+func (p *Pool[T]) serve(h handoff[T]) bool {
+	elem := p.waiters.Front()
+	if p.waitOrder == WaitLIFO {
+		elem = p.waiters.Back()
+	}
+	if elem == nil {
+		return false
+	}
+
+	w := p.waiters.Remove(elem).(*waiter[T])
+	w.elem = nil
+	w.ch <- h
+	return true
+}
+
+// wait blocks until the waiter is served or ctx is done.
+func (p *Pool[T]) wait(ctx context.Context, w *waiter[T]) (handoff[T], error) {
+	select {
+	case h := <-w.ch:
+		if h.closed {
+			return h, ErrPoolClosed
+		}
+		return h, nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	served := w.elem == nil
+	if !served {
+		p.waiters.Remove(w.elem)
+		w.elem = nil
+	}
+	p.mu.Unlock()
+
+	if served {
+		// Served while giving up, pass the resource or slot on so it is not lost.
+		h := <-w.ch
+		p.mu.Lock()
+		switch {
+		case h.slot:
+			p.release()
+		case !h.closed:
+			p.putIdle(h.r)
+		}
+		p.mu.Unlock()
+	}
+	return handoff[T]{}, ErrOutOfResources
+}
+
+// putIdle hands a usable resource to the next waiter or keeps it idle. It requires p.mu.
+func (p *Pool[T]) putIdle(r idleResource[T]) {
+	if p.closed {
+		p.destroy(r.v)
+		go p.discard(r.v)
+		return
+	}
+	if !p.serve(handoff[T]{r: r}) {
+		p.idle = append(p.idle, r)
+	}
+}
+
+// release frees the slot of a destroyed resource, passing it to the next waiter. It requires p.mu.
+func (p *Pool[T]) release() {
+	p.open--
+	if !p.closed && p.open < p.maxSize && p.serve(handoff[T]{slot: true}) {
+		p.open++
+	}
+}
+
+// closeWaiters tells all waiters the pool closed. It requires p.mu.
+func (p *Pool[T]) closeWaiters() {
+	for p.serve(handoff[T]{closed: true}) {
+	}
+}
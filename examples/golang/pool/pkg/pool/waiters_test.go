@@ -0,0 +1,197 @@
+//go:build unit
+// +build unit
+
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func waiterCount[T any](p *Pool[T]) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.waiters.Len()
+}
+
+func TestPoolWaitOrder(t *testing.T) {
+	cases := []struct {
+		name          string
+		order         WaitOrder
+		expectedOrder []int
+	}{
+		{"should-serve-longest-waiting-first", WaitFIFO, []int{0, 1, 2, 3}},
+		{"should-serve-latest-waiting-first", WaitLIFO, []int{3, 2, 1, 0}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pool, err := New(validFactory, WithMaxSize[*mockPoolable](1), WithWaitOrder[*mockPoolable](c.order))
+			require.NoError(t, err)
+			defer pool.Close()
+
+			held, err := pool.Get(context.Background())
+			require.NoError(t, err)
+
+			served := make(chan int, len(c.expectedOrder))
+			errs := make(chan error, len(c.expectedOrder))
+			for i := range c.expectedOrder {
+				go func() {
+					v, err := pool.Get(context.Background())
+					if err != nil {
+						errs <- err
+						return
+					}
+					served <- i
+					errs <- pool.Put(v)
+				}()
+				// Queue the waiters one after another.
+				require.Eventually(t, func() bool { return waiterCount(pool) == i+1 }, time.Second, time.Millisecond)
+			}
+
+			require.NoError(t, pool.Put(held))
+			for range c.expectedOrder {
+				require.NoError(t, <-errs)
+			}
+			var order []int
+			for range c.expectedOrder {
+				order = append(order, <-served)
+			}
+			require.Equal(t, c.expectedOrder, order)
+		})
+	}
+}
+
+func TestPoolIdleOrder(t *testing.T) {
+	cases := []struct {
+		name          string
+		order         WaitOrder
+		expectedIndex int
+	}{
+		{"should-take-longest-idle-first", WaitFIFO, 0},
+		{"should-take-latest-returned-first", WaitLIFO, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pool, err := New(validFactory, WithMaxSize[*mockPoolable](3), WithWaitOrder[*mockPoolable](c.order))
+			require.NoError(t, err)
+			defer pool.Close()
+
+			var returned []*mockPoolable
+			for range 3 {
+				v, err := pool.Get(context.Background())
+				require.NoError(t, err)
+				returned = append(returned, v)
+			}
+			for _, v := range returned {
+				require.NoError(t, pool.Put(v))
+			}
+
+			v, err := pool.Get(context.Background())
+			require.NoError(t, err)
+			require.Same(t, returned[c.expectedIndex], v)
+		})
+	}
+}
+
+func TestPoolCancelledWaiterIsRemoved(t *testing.T) {
+	pool, err := New(validFactory, WithMaxSize[*mockPoolable](1))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	held, err := pool.Get(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = pool.Get(ctx)
+	require.ErrorIs(t, err, ErrOutOfResources)
+	require.Equal(t, 0, waiterCount(pool))
+
+	require.NoError(t, pool.Put(held))
+	require.Equal(t, 1, idleCount(pool))
+}
+
+func TestPoolHandoffToCancelledWaiterIsNotLost(t *testing.T) {
+	pool, err := New(validFactory, WithMaxSize[*mockPoolable](1))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 100; i++ {
+		v, err := pool.Get(context.Background())
+		require.NoError(t, err)
+
+		// Serve the waiter while it gives up, it either takes the resource or passes it on.
+		pool.mu.Lock()
+		w := pool.enqueue()
+		pool.putIdle(idleResource[*mockPoolable]{v: v})
+		pool.mu.Unlock()
+
+		h, err := pool.wait(ctx, w)
+		if err == nil {
+			require.Same(t, v, h.r.v)
+			require.NoError(t, pool.Put(v))
+		} else {
+			require.ErrorIs(t, err, ErrOutOfResources)
+		}
+		require.Equal(t, 1, idleCount(pool))
+	}
+}
+
+func TestPoolWaiterGetsFreedSlot(t *testing.T) {
+	factory, calls := flakyFactory(0)
+	pool, err := New(factory, WithMaxSize[*mockPoolable](1))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	held, err := pool.Get(context.Background())
+	require.NoError(t, err)
+
+	result := make(chan *mockPoolable, 1)
+	errs := make(chan error, 1)
+	go func() {
+		v, err := pool.Get(context.Background())
+		errs <- err
+		result <- v
+	}()
+	require.Eventually(t, func() bool { return waiterCount(pool) == 1 }, time.Second, time.Millisecond)
+
+	// The invalid resource frees its slot, the waiter creates a new resource in it.
+	held.Invalidate()
+	require.NoError(t, pool.Put(held))
+	require.NoError(t, <-errs)
+	v := <-result
+	require.NotSame(t, held, v)
+	require.Equal(t, int32(2), calls.Load())
+	require.Equal(t, 1, pool.Stats().Open)
+}
+
+func TestPoolCloseWakesWaiters(t *testing.T) {
+	pool, err := New(validFactory, WithMaxSize[*mockPoolable](1))
+	require.NoError(t, err)
+
+	_, err = pool.Get(context.Background())
+	require.NoError(t, err)
+
+	errs := make(chan error)
+	go func() {
+		_, err := pool.Get(context.Background())
+		errs <- err
+	}()
+	require.Eventually(t, func() bool { return waiterCount(pool) == 1 }, time.Second, time.Millisecond)
+
+	pool.Close()
+	require.ErrorIs(t, <-errs, ErrPoolClosed)
+}
+
+func TestPoolRejectsInvalidWaitOrder(t *testing.T) {
+	_, err := New(validFactory, WithWaitOrder[*mockPoolable](WaitOrder(2)))
+	require.Error(t, err)
+}